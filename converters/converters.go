@@ -56,6 +56,18 @@ var supportedTypeToConverterType = map[string]string{
 	"interface{}":            "Interface",
 }
 
+var converterTypeToGoType = map[string]string{
+	"Float64": "float64",
+	"Float32": "float32",
+	"Int32":   "int32",
+	"Int64":   "int64",
+	"UInt32":  "uint32",
+	"UInt64":  "uint64",
+	"Bool":    "bool",
+	"String":  "string",
+	"Bytes":   "[]byte",
+}
+
 // Converter is an object to represent a conversion between types.
 type Converter struct {
 	original string
@@ -86,6 +98,21 @@ func ConverterType(protobufType string) (*Converter, error) {
 	}, nil
 }
 
+// GoType gives the Go type of a protobuf scalar type.
+func GoType(protobufType string) (string, error) {
+	c, err := ConverterType(protobufType)
+	if err != nil {
+		return "", err
+	}
+
+	t, ok := converterTypeToGoType[c.String()]
+	if !ok {
+		return "", fmt.Errorf("'%s' is not a protobuf scalar type", protobufType)
+	}
+
+	return t, nil
+}
+
 var conversionMap = map[string]map[string]bool{
 	"String": map[string]bool{
 		"int":        true,
@@ -0,0 +1,64 @@
+package converters
+
+import (
+	"time"
+)
+
+// Scalar is the set of types supported by the slice conversion helpers.
+type Scalar interface {
+	~string | ~bool | ~int | ~int32 | ~int64 | ~uint | ~uint32 | ~uint64 |
+		~float32 | ~float64 | ~[]byte | time.Time
+}
+
+// NilPolicy defines how nil pointers are handled when converting a slice of
+// pointers into a slice of values.
+type NilPolicy int
+
+const (
+	// NilAsZero converts nil pointers into the type zero value.
+	NilAsZero NilPolicy = iota
+
+	// NilSkip removes nil pointers from the output.
+	NilSkip
+)
+
+// SliceToPointers converts a []T into a []*T, where every pointer refers to
+// a copy of its value. A nil slice is converted into a nil slice.
+func SliceToPointers[T Scalar](values []T) []*T {
+	if values == nil {
+		return nil
+	}
+
+	out := make([]*T, len(values))
+	for i := range values {
+		v := values[i]
+		out[i] = &v
+	}
+
+	return out
+}
+
+// PointersToSlice converts a []*T into a []T, handling nil pointers
+// according the policy. A nil slice is converted into a nil slice.
+func PointersToSlice[T Scalar](values []*T, policy NilPolicy) []T {
+	if values == nil {
+		return nil
+	}
+
+	out := make([]T, 0, len(values))
+	for _, v := range values {
+		if v == nil {
+			if policy == NilSkip {
+				continue
+			}
+
+			var zero T
+			out = append(out, zero)
+			continue
+		}
+
+		out = append(out, *v)
+	}
+
+	return out
+}
@@ -3,6 +3,8 @@ package errors
 import (
 	"context"
 	"encoding/json"
+	"math"
+	"time"
 
 	"github.com/rsfreitas/go-pocket-utils/logger"
 )
@@ -11,23 +13,42 @@ import (
 // error log description for the end-user, and it implements the errorApi.Error
 // interface.
 type ServiceError struct {
-	err        *Error
-	attributes []logger.Attribute
-	logger     func(ctx context.Context, msg string, attrs ...logger.Attribute)
+	err          *Error
+	attributes   []logger.Attribute
+	logger       func(ctx context.Context, msg string, attrs ...logger.Attribute)
+	suppressions *suppressionRegistry
+	stack        []uintptr
+	embedAttrs   bool
+	translator   Translator
+	onError      OnErrorFunc
+
+	messageKey    string
+	messageParams map[string]interface{}
 }
 
 type serviceErrorOptions struct {
-	HideDetails bool
-	Code        int32
-	Kind        ErrorKind
-	ServiceName string
-	Message     string
-	Destination string
-	Logger      func(ctx context.Context, msg string, attrs ...logger.Attribute)
-	Error       error
+	HideDetails  bool
+	Code         int32
+	Kind         ErrorKind
+	ServiceName  string
+	Message      string
+	Destination  string
+	Logger       func(ctx context.Context, msg string, attrs ...logger.Attribute)
+	Error        error
+	Suppressions *suppressionRegistry
+	CaptureStack bool
+	Translator   Translator
+	OnError      OnErrorFunc
 }
 
 func newServiceError(options *serviceErrorOptions) *ServiceError {
+	var stack []uintptr
+	if options.CaptureStack {
+		// Skips newServiceError, Factory.newError and the factory method, so
+		// the stack starts where the error was created.
+		stack = captureStack(4)
+	}
+
 	return &ServiceError{
 		err: &Error{
 			hideDetails:   options.HideDetails,
@@ -38,7 +59,11 @@ func newServiceError(options *serviceErrorOptions) *ServiceError {
 			Kind:          options.Kind,
 			SublevelError: options.Error,
 		},
-		logger: options.Logger,
+		logger:       options.Logger,
+		suppressions: options.Suppressions,
+		stack:        stack,
+		translator:   options.Translator,
+		onError:      options.OnError,
 	}
 }
 
@@ -47,22 +72,93 @@ func (s *ServiceError) WithCode(code int32) *ServiceError {
 	return s
 }
 
+// WithDestination sets the destination, i.e., the resource or service that
+// the request was sent to, related to the error.
+func (s *ServiceError) WithDestination(destination string) *ServiceError {
+	s.err.Destination = destination
+	return s
+}
+
+// WithRetryable sets if the client can safely retry the request.
+func (s *ServiceError) WithRetryable(retryable bool) *ServiceError {
+	s.err.Retryable = retryable
+	return s
+}
+
+// WithRetryAfter sets how long the client should wait before retrying the
+// request. It also marks the error as retryable.
+func (s *ServiceError) WithRetryAfter(d time.Duration) *ServiceError {
+	s.err.Retryable = true
+	s.err.RetryAfter = int64(math.Ceil(d.Seconds()))
+	return s
+}
+
+// WithMetadata adds custom information, sent to the client, into the error.
+func (s *ServiceError) WithMetadata(metadata map[string]string) *ServiceError {
+	if s.err.Metadata == nil {
+		s.err.Metadata = make(map[string]string, len(metadata))
+	}
+	for k, v := range metadata {
+		s.err.Metadata[k] = v
+	}
+
+	return s
+}
+
+// WithFields sets the request fields that failed validation.
+func (s *ServiceError) WithFields(fields []FieldViolation) *ServiceError {
+	s.err.Fields = fields
+	return s
+}
+
 func (s *ServiceError) WithAttributes(attrs ...logger.Attribute) *ServiceError {
 	s.attributes = attrs
 	return s
 }
 
+// EmbedAttributes makes the attributes set with WithAttributes also be
+// sent inside the error, as its attributes map, so downstream services and
+// gateways can log them.
+func (s *ServiceError) EmbedAttributes() *ServiceError {
+	s.embedAttrs = true
+	return s
+}
+
 func (s *ServiceError) Submit(ctx context.Context) error {
+	if s.onError != nil {
+		s.onError(s.err.Kind, s.err.Code, s.err.ServiceName)
+	}
+
+	// Suppressed errors are always counted, even when they are not logged.
+	log := s.logger
+	if s.suppressions.suppress(s.err.Kind, s.err.Destination) {
+		log = s.suppressions.logger
+	}
+
 	// Display the error message onto the output
-	if s.logger != nil {
+	if log != nil {
 		logFields := []logger.Attribute{withKind(s.err.Kind)}
 		if s.err.SublevelError != nil {
 			logFields = append(logFields, logger.Error(s.err.SublevelError))
 		}
+		if len(s.stack) > 0 {
+			logFields = append(logFields, logger.String("error.stack", formatStack(s.stack)))
+		}
 
-		s.logger(ctx, s.err.Message, append(logFields, s.attributes...)...)
+		log(ctx, s.err.Message, append(logFields, s.attributes...)...)
 	}
 
+	if s.embedAttrs && len(s.attributes) > 0 {
+		s.err.Attributes = make(map[string]interface{}, len(s.attributes))
+		for _, attr := range s.attributes {
+			s.err.Attributes[attr.Key()] = attr.Value()
+		}
+	}
+
+	// Logs are kept with the original message, only the client receives
+	// it translated.
+	s.translate(ctx)
+
 	// And give back the proper error for the API
 	return s.err
 }
@@ -82,29 +178,69 @@ type Error struct {
 	Kind          ErrorKind `json:"kind"`
 	SublevelError error     `json:"details,omitempty"`
 
+	// Retryable tells the client if the request can be safely retried.
+	Retryable bool `json:"retryable,omitempty"`
+
+	// RetryAfter is how long, in seconds, the client should wait before
+	// retrying the request.
+	RetryAfter int64 `json:"retry_after,omitempty"`
+
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// Fields holds the request fields that failed validation.
+	Fields []FieldViolation `json:"fields,omitempty"`
+
+	// Attributes holds the log attributes embedded into the error with
+	// ServiceError.EmbedAttributes.
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+
 	hideDetails bool
 }
 
+// FieldViolation describes a request field that failed validation.
+type FieldViolation struct {
+	Field    string `json:"field"`
+	Message  string `json:"message"`
+	Location string `json:"location,omitempty"`
+}
+
 func (e *Error) Error() string {
 	return e.String()
 }
 
+// Unwrap gives the error that caused this error, allowing the standard
+// errors package to inspect it.
+func (e *Error) Unwrap() error {
+	return e.SublevelError
+}
+
 func (e *Error) String() string {
+	b, _ := json.Marshal(e.Public())
+	return string(b)
+}
+
+// Public gives a copy of the error holding only what can be sent to the
+// client, i.e., without the details hidden by the factory.
+func (e *Error) Public() *Error {
 	out := Error{
 		Code:        e.Code,
 		Destination: e.Destination,
 		Kind:        e.Kind,
 		Message:     e.Message,
+		Retryable:   e.Retryable,
+		RetryAfter:  e.RetryAfter,
+		Metadata:    e.Metadata,
+		Fields:      e.Fields,
 	}
 
 	// The framework can be initialized disabling error message details at the
 	// output to avoid showing internal information.
 	if !e.hideDetails {
+		out.Attributes = e.Attributes
 		out.SublevelError = e.SublevelError
 		out.ServiceName = e.ServiceName
 		out.Destination = e.Destination
 	}
 
-	b, _ := json.Marshal(out)
-	return string(b)
+	return &out
 }
@@ -0,0 +1,61 @@
+// Package errorsprom provides a Prometheus counter of submitted errors,
+// to be used as errors.FactoryOptions.OnError.
+package errorsprom
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/rsfreitas/go-pocket-utils/errors"
+)
+
+// CounterOptions are the options used to create a Counter.
+type CounterOptions struct {
+	Namespace string
+	Subsystem string
+
+	// Name is the metric name, service_errors_total by default.
+	Name string
+
+	// Registerer is where the counter is registered, the Prometheus default
+	// registerer if not set.
+	Registerer prometheus.Registerer
+}
+
+// Counter counts submitted errors by kind, code and service name.
+type Counter struct {
+	vec *prometheus.CounterVec
+}
+
+// NewCounter creates and registers a new Counter.
+func NewCounter(options CounterOptions) (*Counter, error) {
+	name := options.Name
+	if name == "" {
+		name = "service_errors_total"
+	}
+
+	registerer := options.Registerer
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	vec := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: options.Namespace,
+		Subsystem: options.Subsystem,
+		Name:      name,
+		Help:      "Number of errors submitted by kind, code and service.",
+	}, []string{"kind", "code", "service"})
+
+	if err := registerer.Register(vec); err != nil {
+		return nil, err
+	}
+
+	return &Counter{vec: vec}, nil
+}
+
+// OnError increments the counter. It must be used as
+// errors.FactoryOptions.OnError.
+func (c *Counter) OnError(kind errors.ErrorKind, code int32, serviceName string) {
+	c.vec.WithLabelValues(string(kind), strconv.Itoa(int(code)), serviceName).Inc()
+}
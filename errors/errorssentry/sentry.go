@@ -0,0 +1,72 @@
+// Package errorssentry provides a Sentry reporter of submitted errors, to
+// be used as errors.FactoryOptions.OnError.
+package errorssentry
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/getsentry/sentry-go"
+
+	"github.com/rsfreitas/go-pocket-utils/errors"
+)
+
+// ReporterOptions are the options used to create a Reporter.
+type ReporterOptions struct {
+	// Hub is the Sentry hub used to send events, the current hub if not
+	// set.
+	Hub *sentry.Hub
+
+	// Kinds are the error kinds reported. By default, only internal and
+	// unavailable errors are reported, since other kinds are usually
+	// caused by clients.
+	Kinds []errors.ErrorKind
+}
+
+// Reporter sends an event to Sentry for every submitted error of the
+// reported kinds.
+type Reporter struct {
+	hub   *sentry.Hub
+	kinds map[errors.ErrorKind]bool
+}
+
+// NewReporter creates a new Reporter.
+func NewReporter(options ReporterOptions) *Reporter {
+	hub := options.Hub
+	if hub == nil {
+		hub = sentry.CurrentHub()
+	}
+
+	kinds := options.Kinds
+	if len(kinds) == 0 {
+		kinds = []errors.ErrorKind{errors.KindInternal, errors.KindUnavailable}
+	}
+
+	r := &Reporter{
+		hub:   hub,
+		kinds: make(map[errors.ErrorKind]bool, len(kinds)),
+	}
+	for _, kind := range kinds {
+		r.kinds[kind] = true
+	}
+
+	return r
+}
+
+// OnError reports the error to Sentry. It must be used as
+// errors.FactoryOptions.OnError.
+func (r *Reporter) OnError(kind errors.ErrorKind, code int32, serviceName string) {
+	if !r.kinds[kind] {
+		return
+	}
+
+	// Errors are submitted concurrently, so every report uses its own hub
+	// to keep its tags apart from the others.
+	hub := r.hub.Clone()
+	hub.ConfigureScope(func(scope *sentry.Scope) {
+		scope.SetTag("error.kind", string(kind))
+		scope.SetTag("error.code", strconv.Itoa(int(code)))
+		scope.SetTag("service", serviceName)
+	})
+	hub.CaptureMessage(fmt.Sprintf("%s: %s", serviceName, kind))
+}
@@ -0,0 +1,275 @@
+// Package errorstest provides a conformance suite that services can run to
+// verify that their custom encoders and decoders remain compatible with the
+// canonical errors.Error schema.
+package errorstest
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/rsfreitas/go-pocket-utils/errors"
+)
+
+//go:embed golden/*.json
+var golden embed.FS
+
+// Codec is the behavior that a custom errors.Error encoder/decoder must
+// implement to be checked by the conformance suite.
+type Codec interface {
+	Encode(e *errors.Error) ([]byte, error)
+	Decode(data []byte) (*errors.Error, error)
+}
+
+// JSONCodec is the canonical Codec implementation, using the errors.Error
+// JSON representation.
+type JSONCodec struct{}
+
+func (JSONCodec) Encode(e *errors.Error) ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+func (JSONCodec) Decode(data []byte) (*errors.Error, error) {
+	var e wireError
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+
+	out := &errors.Error{
+		Code:        e.Code,
+		ServiceName: e.ServiceName,
+		Message:     e.Message,
+		Destination: e.Destination,
+		Kind:        e.Kind,
+		Retryable:   e.Retryable,
+		RetryAfter:  e.RetryAfter,
+		Metadata:    e.Metadata,
+		Fields:      e.Fields,
+		Attributes:  e.Attributes,
+	}
+	if len(e.Details) > 0 && string(e.Details) != "null" {
+		out.SublevelError = Details(e.Details)
+	}
+
+	return out, nil
+}
+
+// wireError is the JSON representation of errors.Error. Its details are
+// kept raw, since they cannot be decoded into an error.
+type wireError struct {
+	Code        int32                   `json:"code"`
+	ServiceName string                  `json:"service_name"`
+	Message     string                  `json:"message"`
+	Destination string                  `json:"destination"`
+	Kind        errors.ErrorKind        `json:"kind"`
+	Details     json.RawMessage         `json:"details"`
+	Retryable   bool                    `json:"retryable"`
+	RetryAfter  int64                   `json:"retry_after"`
+	Metadata    map[string]string       `json:"metadata"`
+	Fields      []errors.FieldViolation `json:"fields"`
+	Attributes  map[string]interface{}  `json:"attributes"`
+}
+
+// Details is an error holding the raw JSON details of a decoded error. It
+// is encoded back exactly as it was received.
+type Details json.RawMessage
+
+func (d Details) Error() string {
+	return string(d)
+}
+
+func (d Details) MarshalJSON() ([]byte, error) {
+	return d, nil
+}
+
+// Case is a golden corpus entry, holding the error value and the name of
+// the file with its canonical JSON representation.
+type Case struct {
+	Name  string
+	Error *errors.Error
+}
+
+// Cases gives all entries of the golden corpus.
+func Cases() []Case {
+	return []Case{
+		{
+			Name: "validation",
+			Error: &errors.Error{
+				Code:        errors.CodeInvalidArgument,
+				ServiceName: "users",
+				Message:     "request validation failed",
+				Kind:        errors.KindValidation,
+			},
+		},
+		{
+			Name: "internal",
+			Error: &errors.Error{
+				Code:        errors.CodeInternal,
+				ServiceName: "users",
+				Message:     "got an internal error",
+				Kind:        errors.KindInternal,
+			},
+		},
+		{
+			Name: "not_found",
+			Error: &errors.Error{
+				Code:        errors.CodeNotFound,
+				ServiceName: "users",
+				Message:     "not found",
+				Destination: "users.get",
+				Kind:        errors.KindNotFound,
+			},
+		},
+		{
+			Name: "precondition",
+			Error: &errors.Error{
+				Code:        errors.CodePreconditionFailed,
+				ServiceName: "users",
+				Message:     "failed precondition",
+				Kind:        errors.KindPrecondition,
+			},
+		},
+		{
+			Name: "permission",
+			Error: &errors.Error{
+				Code:        errors.CodeNoPermission,
+				ServiceName: "users",
+				Message:     "no permission to access users",
+				Kind:        errors.KindPermission,
+			},
+		},
+		{
+			Name: "details",
+			Error: &errors.Error{
+				Code:          errors.CodeInvalidArgument,
+				ServiceName:   "users",
+				Message:       "request validation failed",
+				Kind:          errors.KindValidation,
+				SublevelError: Details(`{"name":"is required"}`),
+			},
+		},
+		{
+			Name: "fields",
+			Error: &errors.Error{
+				Code:        errors.CodeInvalidArgument,
+				ServiceName: "users",
+				Message:     "request validation failed",
+				Kind:        errors.KindValidation,
+				Fields: []errors.FieldViolation{
+					{Field: "name", Message: "field is required", Location: "body"},
+					{Field: "age", Message: "failed on the 'gte=18' validation"},
+				},
+			},
+		},
+		{
+			Name: "retry",
+			Error: &errors.Error{
+				Code:        errors.CodeUnavailable,
+				ServiceName: "users",
+				Message:     "service unavailable",
+				Kind:        errors.KindUnavailable,
+				Retryable:   true,
+				RetryAfter:  30,
+				Metadata:    map[string]string{"region": "us-east-1"},
+			},
+		},
+		{
+			Name: "attributes",
+			Error: &errors.Error{
+				Code:        errors.CodeNotFound,
+				ServiceName: "users",
+				Message:     "not found",
+				Kind:        errors.KindNotFound,
+				Attributes: map[string]interface{}{
+					"request_id": "6f1c2a",
+					"user_id":    float64(42),
+				},
+			},
+		},
+		{
+			Name: "minimal",
+			Error: &errors.Error{
+				Code: errors.CodeInternal,
+				Kind: errors.KindInternal,
+			},
+		},
+	}
+}
+
+// Golden gives the canonical JSON representation of a corpus entry.
+func Golden(name string) ([]byte, error) {
+	return golden.ReadFile(fmt.Sprintf("golden/%s.json", name))
+}
+
+// RunConformance checks, for every golden corpus entry, if codec encodes
+// errors equivalently to the canonical JSON, decodes the canonical JSON
+// into the same error and supports round-trips.
+func RunConformance(t *testing.T, codec Codec) {
+	t.Helper()
+
+	for _, c := range Cases() {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			data, err := Golden(c.Name)
+			if err != nil {
+				t.Fatalf("could not load golden file: %v", err)
+			}
+
+			t.Run("canonical", func(t *testing.T) {
+				assertEqualJSON(t, []byte(c.Error.String()), data)
+			})
+
+			t.Run("encode", func(t *testing.T) {
+				out, err := codec.Encode(c.Error)
+				if err != nil {
+					t.Fatalf("could not encode: %v", err)
+				}
+
+				assertEqualJSON(t, out, data)
+			})
+
+			t.Run("decode", func(t *testing.T) {
+				e, err := codec.Decode(data)
+				if err != nil {
+					t.Fatalf("could not decode: %v", err)
+				}
+
+				assertEqualJSON(t, []byte(e.String()), data)
+			})
+
+			t.Run("round-trip", func(t *testing.T) {
+				out, err := codec.Encode(c.Error)
+				if err != nil {
+					t.Fatalf("could not encode: %v", err)
+				}
+
+				e, err := codec.Decode(out)
+				if err != nil {
+					t.Fatalf("could not decode: %v", err)
+				}
+
+				assertEqualJSON(t, []byte(e.String()), data)
+			})
+		})
+	}
+}
+
+// assertEqualJSON compares two JSON documents semantically, i.e., ignoring
+// fields order and formatting.
+func assertEqualJSON(t *testing.T, got, want []byte) {
+	t.Helper()
+
+	var g, w interface{}
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("invalid json '%s': %v", got, err)
+	}
+	if err := json.Unmarshal(want, &w); err != nil {
+		t.Fatalf("invalid golden json '%s': %v", want, err)
+	}
+
+	if !reflect.DeepEqual(g, w) {
+		t.Errorf("incompatible error json:\n got: %s\nwant: %s", got, want)
+	}
+}
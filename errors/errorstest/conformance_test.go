@@ -0,0 +1,9 @@
+package errorstest
+
+import (
+	"testing"
+)
+
+func TestJSONCodecConformance(t *testing.T) {
+	RunConformance(t, JSONCodec{})
+}
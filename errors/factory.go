@@ -1,9 +1,12 @@
 package errors
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
+	"github.com/go-playground/validator/v10"
+
 	"github.com/rsfreitas/go-pocket-utils/logger"
 )
 
@@ -14,104 +17,213 @@ const (
 	CodeInvalidArgument
 	CodePreconditionFailed
 	CodeNoPermission
+	CodeResourceExhausted
+	CodeUnauthenticated
+	CodeAlreadyExists
+	CodeUnavailable
+	CodeDeadlineExceeded
+	CodeNotImplemented
 )
 
 // ErrorKind is an error representation of a mapped error.
 type ErrorKind string
 
 var (
-	KindValidation   ErrorKind = "ValidationError"
-	KindInternal     ErrorKind = "InternalError"
-	KindNotFound     ErrorKind = "NotFoundError"
-	KindPrecondition ErrorKind = "ConditionError"
-	KindPermission   ErrorKind = "PermissionError"
+	KindValidation        ErrorKind = "ValidationError"
+	KindInternal          ErrorKind = "InternalError"
+	KindNotFound          ErrorKind = "NotFoundError"
+	KindPrecondition      ErrorKind = "ConditionError"
+	KindPermission        ErrorKind = "PermissionError"
+	KindResourceExhausted ErrorKind = "ResourceExhaustedError"
+	KindUnauthenticated   ErrorKind = "UnauthenticatedError"
+	KindConflict          ErrorKind = "ConflictError"
+	KindUnavailable       ErrorKind = "UnavailableError"
+	KindTimeout           ErrorKind = "TimeoutError"
+	KindNotImplemented    ErrorKind = "NotImplementedError"
 )
 
+// OnErrorFunc is a function called whenever an error is submitted.
+type OnErrorFunc func(kind ErrorKind, code int32, serviceName string)
+
+// OnErrorHooks combines several OnErrorFunc into a single one, calling
+// them in order.
+func OnErrorHooks(hooks ...OnErrorFunc) OnErrorFunc {
+	return func(kind ErrorKind, code int32, serviceName string) {
+		for _, hook := range hooks {
+			hook(kind, code, serviceName)
+		}
+	}
+}
+
 type Factory struct {
 	hideMessageDetails bool
 	serviceName        string
 	logger             *logger.Logger
+	suppressions       *suppressionRegistry
+	captureStack       bool
+	translator         Translator
+	logLevels          map[ErrorKind]Level
+	onError            OnErrorFunc
 }
 
 type FactoryOptions struct {
 	HideMessageDetails bool
 	ServiceName        string
-	Logger             *logger.Logger
+
+	// Logger is optional, errors are not logged without it.
+	Logger *logger.Logger
+
+	// CaptureStackTraces captures the call stack when errors are created,
+	// adding it to the log record emitted by Submit. It is never sent to
+	// clients.
+	CaptureStackTraces bool
+
+	// Translator, when set, translates the client-facing message of errors
+	// into the locale found in the context given to Submit (see
+	// WithLocale). Logs always keep the original message.
+	Translator Translator
+
+	// LogLevelByKind overrides the log level used by errors of each kind.
+	LogLevelByKind map[ErrorKind]Level
+
+	// OnError is called by every error submitted, allowing error rates to
+	// be observed. See OnErrorHooks to call more than one function.
+	OnError OnErrorFunc
 }
 
 // NewFactory creates a new Factory object.
 func NewFactory(options FactoryOptions) *Factory {
+	var debug func(ctx context.Context, msg string, attrs ...logger.Attribute)
+	if options.Logger != nil {
+		debug = options.Logger.Debug
+	}
+
 	return &Factory{
 		serviceName:        options.ServiceName,
 		logger:             options.Logger,
 		hideMessageDetails: options.HideMessageDetails,
+		suppressions:       newSuppressionRegistry(debug),
+		captureStack:       options.CaptureStackTraces,
+		translator:         options.Translator,
+		logLevels:          options.LogLevelByKind,
+		onError:            options.OnError,
 	}
 }
 
+// newError creates a ServiceError holding every option of the factory.
+func (f *Factory) newError(kind ErrorKind, code int32, message string, err error) *ServiceError {
+	return newServiceError(&serviceErrorOptions{
+		HideDetails:  f.hideMessageDetails,
+		Code:         code,
+		Kind:         kind,
+		ServiceName:  f.serviceName,
+		Message:      message,
+		Logger:       f.log(kind),
+		Suppressions: f.suppressions,
+		CaptureStack: f.captureStack,
+		Translator:   f.translator,
+		OnError:      f.onError,
+		Error:        err,
+	})
+}
+
 // InvalidArgument sets that the current error is related to an argument that
 // didn't follow validation rules.
 func (f *Factory) InvalidArgument(err error) *ServiceError {
-	return newServiceError(&serviceErrorOptions{
-		HideDetails: f.hideMessageDetails,
-		Code:        CodeInvalidArgument,
-		Kind:        KindValidation,
-		ServiceName: f.serviceName,
-		Message:     "request validation failed",
-		Logger:      f.logger.Warn,
-		Error:       err,
-	})
+	return f.newError(KindValidation, CodeInvalidArgument, "request validation failed", err)
+}
+
+// InvalidArgumentFromValidator is like InvalidArgument, but it also sets
+// every field that failed validation into the error, so they can be sent
+// to the client.
+func (f *Factory) InvalidArgumentFromValidator(err validator.ValidationErrors) *ServiceError {
+	fields := make([]FieldViolation, len(err))
+	for i, fe := range err {
+		fields[i] = FieldViolation{
+			Field:    fe.Field(),
+			Message:  validationMessage(fe),
+			Location: "body",
+		}
+	}
+
+	// Not built through InvalidArgument, so the captured stack skips the
+	// same number of frames of every other factory method.
+	return f.newError(KindValidation, CodeInvalidArgument, "request validation failed", err).WithFields(fields)
+}
+
+func validationMessage(fe validator.FieldError) string {
+	if fe.Tag() == "required" {
+		return "field is required"
+	}
+	if fe.Param() != "" {
+		return fmt.Sprintf("failed on the '%s=%s' validation", fe.Tag(), fe.Param())
+	}
+
+	return fmt.Sprintf("failed on the '%s' validation", fe.Tag())
 }
 
 // FailedPrecondition sets that the current error is related to an internal
 // condition which wasn't satisfied.
 func (f *Factory) FailedPrecondition(message string) *ServiceError {
-	return newServiceError(&serviceErrorOptions{
-		HideDetails: f.hideMessageDetails,
-		Code:        CodePreconditionFailed,
-		Kind:        KindPrecondition,
-		ServiceName: f.serviceName,
-		Message:     "failed precondition",
-		Logger:      f.logger.Warn,
-		Error:       errors.New(message),
-	})
+	return f.newError(KindPrecondition, CodePreconditionFailed, "failed precondition", errors.New(message))
 }
 
 // NotFound sets that the current error is related to some data not being found,
 // probably in the database.
 func (f *Factory) NotFound() *ServiceError {
-	return newServiceError(&serviceErrorOptions{
-		HideDetails: f.hideMessageDetails,
-		Code:        CodeNotFound,
-		Kind:        KindNotFound,
-		ServiceName: f.serviceName,
-		Message:     "not found",
-		Logger:      f.logger.Warn,
-	})
+	return f.newError(KindNotFound, CodeNotFound, "not found", nil)
 }
 
 // Internal sets that the current error is related to an internal service
 // error.
 func (f *Factory) Internal(err error) *ServiceError {
-	return newServiceError(&serviceErrorOptions{
-		HideDetails: f.hideMessageDetails,
-		Code:        CodeInternal,
-		Kind:        KindInternal,
-		ServiceName: f.serviceName,
-		Message:     "got an internal error",
-		Logger:      f.logger.Error,
-		Error:       err,
-	})
+	return f.newError(KindInternal, CodeInternal, "got an internal error", err)
 }
 
 // PermissionDenied sets that the current error is related to a client trying
 // to access a resource without having permission to do so.
 func (f *Factory) PermissionDenied() *ServiceError {
-	return newServiceError(&serviceErrorOptions{
-		HideDetails: f.hideMessageDetails,
-		Code:        CodeNoPermission,
-		Kind:        KindPermission,
-		ServiceName: f.serviceName,
-		Message:     fmt.Sprintf("no permission to access %s", f.serviceName),
-		Logger:      f.logger.Info,
-	})
+	return f.newError(KindPermission, CodeNoPermission, fmt.Sprintf("no permission to access %s", f.serviceName), nil)
+}
+
+// Unauthenticated sets that the current error is related to a client
+// without valid credentials.
+func (f *Factory) Unauthenticated() *ServiceError {
+	return f.newError(KindUnauthenticated, CodeUnauthenticated, "unauthenticated", nil)
+}
+
+// AlreadyExists sets that the current error is related to a resource that
+// the client tried to create but already exists.
+func (f *Factory) AlreadyExists() *ServiceError {
+	return f.newError(KindConflict, CodeAlreadyExists, "already exists", nil)
+}
+
+// Conflict sets that the current error is related to a request conflicting
+// with the current state of a resource.
+func (f *Factory) Conflict(message string) *ServiceError {
+	return f.newError(KindConflict, CodeAlreadyExists, "conflict", errors.New(message))
+}
+
+// ResourceExhausted sets that the current error is related to a client
+// that exceeded some limit, like a rate limit or a quota.
+func (f *Factory) ResourceExhausted() *ServiceError {
+	return f.newError(KindResourceExhausted, CodeResourceExhausted, "resource exhausted", nil)
+}
+
+// Unavailable sets that the current error is related to a service or
+// dependency that is temporarily unavailable.
+func (f *Factory) Unavailable(err error) *ServiceError {
+	return f.newError(KindUnavailable, CodeUnavailable, "service unavailable", err)
+}
+
+// DeadlineExceeded sets that the current error is related to an operation
+// that didn't finish in time.
+func (f *Factory) DeadlineExceeded() *ServiceError {
+	return f.newError(KindTimeout, CodeDeadlineExceeded, "deadline exceeded", nil)
+}
+
+// NotImplemented sets that the current error is related to an operation
+// that is not supported by the service.
+func (f *Factory) NotImplemented() *ServiceError {
+	return f.newError(KindNotImplemented, CodeNotImplemented, "not implemented", nil)
 }
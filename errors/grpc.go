@@ -0,0 +1,228 @@
+package errors
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// metadataPrefix namespaces the error metadata inside the gRPC ErrorInfo
+// metadata.
+const metadataPrefix = "metadata."
+
+var kindToGRPCCode = map[ErrorKind]codes.Code{
+	KindValidation:        codes.InvalidArgument,
+	KindInternal:          codes.Internal,
+	KindNotFound:          codes.NotFound,
+	KindPrecondition:      codes.FailedPrecondition,
+	KindPermission:        codes.PermissionDenied,
+	KindResourceExhausted: codes.ResourceExhausted,
+	KindUnauthenticated:   codes.Unauthenticated,
+	KindConflict:          codes.AlreadyExists,
+	KindUnavailable:       codes.Unavailable,
+	KindTimeout:           codes.DeadlineExceeded,
+	KindNotImplemented:    codes.Unimplemented,
+}
+
+var kindToCode = map[ErrorKind]int32{
+	KindValidation:        CodeInvalidArgument,
+	KindInternal:          CodeInternal,
+	KindNotFound:          CodeNotFound,
+	KindPrecondition:      CodePreconditionFailed,
+	KindPermission:        CodeNoPermission,
+	KindResourceExhausted: CodeResourceExhausted,
+	KindUnauthenticated:   CodeUnauthenticated,
+	KindConflict:          CodeAlreadyExists,
+	KindUnavailable:       CodeUnavailable,
+	KindTimeout:           CodeDeadlineExceeded,
+	KindNotImplemented:    CodeNotImplemented,
+}
+
+// GRPCStatus gives the gRPC status of the error, carrying the structured
+// error as an ErrorInfo detail. It allows gRPC servers to send the error
+// with the proper status code. The status message keeps the JSON
+// representation of the error for clients that still parse it.
+func (e *Error) GRPCStatus() *status.Status {
+	out := e.Public()
+
+	code, ok := kindToGRPCCode[out.Kind]
+	if !ok {
+		code = codes.Unknown
+	}
+
+	metadata := map[string]string{
+		"code":    strconv.Itoa(int(out.Code)),
+		"message": out.Message,
+	}
+	if out.Destination != "" {
+		metadata["destination"] = out.Destination
+	}
+	if out.SublevelError != nil {
+		if b, err := json.Marshal(out.SublevelError); err == nil {
+			metadata["details"] = string(b)
+		}
+	}
+	if out.Retryable {
+		metadata["retryable"] = strconv.FormatBool(out.Retryable)
+	}
+	if out.RetryAfter > 0 {
+		metadata["retry_after"] = strconv.FormatInt(out.RetryAfter, 10)
+	}
+	for k, v := range out.Metadata {
+		metadata[metadataPrefix+k] = v
+	}
+	if len(out.Fields) > 0 {
+		b, _ := json.Marshal(out.Fields)
+		metadata["fields"] = string(b)
+	}
+	if len(out.Attributes) > 0 {
+		b, _ := json.Marshal(out.Attributes)
+		metadata["attributes"] = string(b)
+	}
+
+	sts := status.New(code, out.String())
+	withDetails, err := sts.WithDetails(&errdetails.ErrorInfo{
+		Reason:   string(out.Kind),
+		Domain:   out.ServiceName,
+		Metadata: metadata,
+	})
+	if err != nil {
+		return sts
+	}
+
+	return withDetails
+}
+
+// GRPCStatus gives the gRPC status of the error. See Error.GRPCStatus.
+func (s *ServiceError) GRPCStatus() *status.Status {
+	return s.err.GRPCStatus()
+}
+
+// FromGRPCStatus recovers the structured error sent by a gRPC service. When
+// the status does not carry one, an error with the status message and the
+// kind matching its code is returned.
+func FromGRPCStatus(sts *status.Status) *Error {
+	for _, detail := range sts.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok {
+			continue
+		}
+
+		code, _ := strconv.ParseInt(info.Metadata["code"], 10, 32)
+		e := &Error{
+			Code:        int32(code),
+			ServiceName: info.Domain,
+			Message:     info.Metadata["message"],
+			Destination: info.Metadata["destination"],
+			Kind:        ErrorKind(info.Reason),
+		}
+		if details, ok := info.Metadata["details"]; ok {
+			e.SublevelError = newRawDetails(details)
+		}
+		e.Retryable, _ = strconv.ParseBool(info.Metadata["retryable"])
+		e.RetryAfter, _ = strconv.ParseInt(info.Metadata["retry_after"], 10, 64)
+		if fields, ok := info.Metadata["fields"]; ok {
+			_ = json.Unmarshal([]byte(fields), &e.Fields)
+		}
+		if attrs, ok := info.Metadata["attributes"]; ok {
+			_ = json.Unmarshal([]byte(attrs), &e.Attributes)
+		}
+		for k, v := range info.Metadata {
+			if key, ok := strings.CutPrefix(k, metadataPrefix); ok {
+				if e.Metadata == nil {
+					e.Metadata = make(map[string]string)
+				}
+				e.Metadata[key] = v
+			}
+		}
+
+		return e
+	}
+
+	// Services not sending details may still send the error as JSON.
+	var legacy jsonError
+	if err := json.Unmarshal([]byte(sts.Message()), &legacy); err == nil && legacy.Kind != "" {
+		return legacy.toError()
+	}
+
+	e := &Error{
+		Code:    CodeInternal,
+		Message: sts.Message(),
+		Kind:    KindInternal,
+	}
+	for kind, c := range kindToGRPCCode {
+		if c == sts.Code() {
+			e.Kind, e.Code = kind, kindToCode[kind]
+			break
+		}
+	}
+
+	return e
+}
+
+// jsonError is the JSON representation of an Error, used to decode errors
+// whose details are not structured.
+type jsonError struct {
+	Code        int32                  `json:"code"`
+	ServiceName string                 `json:"service_name"`
+	Message     string                 `json:"message"`
+	Destination string                 `json:"destination"`
+	Kind        ErrorKind              `json:"kind"`
+	Details     json.RawMessage        `json:"details"`
+	Retryable   bool                   `json:"retryable"`
+	RetryAfter  int64                  `json:"retry_after"`
+	Metadata    map[string]string      `json:"metadata"`
+	Fields      []FieldViolation       `json:"fields"`
+	Attributes  map[string]interface{} `json:"attributes"`
+}
+
+func (j *jsonError) toError() *Error {
+	e := &Error{
+		Code:        j.Code,
+		ServiceName: j.ServiceName,
+		Message:     j.Message,
+		Destination: j.Destination,
+		Kind:        j.Kind,
+		Retryable:   j.Retryable,
+		RetryAfter:  j.RetryAfter,
+		Metadata:    j.Metadata,
+		Fields:      j.Fields,
+		Attributes:  j.Attributes,
+	}
+	if len(j.Details) > 0 && string(j.Details) != "null" {
+		e.SublevelError = newRawDetails(string(j.Details))
+	}
+
+	return e
+}
+
+// rawDetails is an error holding the JSON details of an error received from
+// another service. It is encoded back exactly as it was received.
+type rawDetails json.RawMessage
+
+func newRawDetails(details string) error {
+	if !json.Valid([]byte(details)) {
+		// Older services send the details as plain text.
+		b, _ := json.Marshal(details)
+		return rawDetails(b)
+	}
+
+	return rawDetails(details)
+}
+
+func (d rawDetails) Error() string {
+	var s string
+	if err := json.Unmarshal(d, &s); err == nil {
+		return s
+	}
+
+	return string(d)
+}
+
+func (d rawDetails) MarshalJSON() ([]byte, error) {
+	return d, nil
+}
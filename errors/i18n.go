@@ -0,0 +1,57 @@
+package errors
+
+import (
+	"context"
+)
+
+// Translator renders the client-facing message of errors in a locale.
+type Translator interface {
+	// Translate gives the message of key in locale, replacing its
+	// parameters. It returns false when there is no translation, keeping
+	// the original message.
+	Translate(locale, key string, params map[string]interface{}) (string, bool)
+}
+
+type localeContextKey struct{}
+
+// WithLocale gives a context holding the locale used to translate error
+// messages, usually taken from the request Accept-Language header.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeContextKey{}, locale)
+}
+
+// LocaleFromContext gives the locale set with WithLocale.
+func LocaleFromContext(ctx context.Context) (string, bool) {
+	locale, ok := ctx.Value(localeContextKey{}).(string)
+	return locale, ok && locale != ""
+}
+
+// WithMessageKey sets the key (and its parameters) used to translate the
+// error message. When not set, the error kind is used as key.
+func (s *ServiceError) WithMessageKey(key string, params map[string]interface{}) *ServiceError {
+	s.messageKey = key
+	s.messageParams = params
+	return s
+}
+
+// translate replaces the client-facing message by its translation in the
+// locale of ctx, if any.
+func (s *ServiceError) translate(ctx context.Context) {
+	if s.translator == nil || ctx == nil {
+		return
+	}
+
+	locale, ok := LocaleFromContext(ctx)
+	if !ok {
+		return
+	}
+
+	key := s.messageKey
+	if key == "" {
+		key = string(s.err.Kind)
+	}
+
+	if msg, ok := s.translator.Translate(locale, key, s.messageParams); ok {
+		s.err.Message = msg
+	}
+}
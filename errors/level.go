@@ -0,0 +1,60 @@
+package errors
+
+import (
+	"context"
+
+	"github.com/rsfreitas/go-pocket-utils/logger"
+)
+
+// Level is the log level used when an error is submitted.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// defaultLogLevels are the log levels of every kind when not overridden by
+// FactoryOptions.LogLevelByKind.
+var defaultLogLevels = map[ErrorKind]Level{
+	KindValidation:        LevelWarn,
+	KindInternal:          LevelError,
+	KindNotFound:          LevelWarn,
+	KindPrecondition:      LevelWarn,
+	KindPermission:        LevelInfo,
+	KindResourceExhausted: LevelWarn,
+	KindUnauthenticated:   LevelInfo,
+	KindConflict:          LevelWarn,
+	KindUnavailable:       LevelError,
+	KindTimeout:           LevelWarn,
+	KindNotImplemented:    LevelWarn,
+}
+
+// log gives the logger function used by errors of a kind, or nil if the
+// factory has no logger.
+func (f *Factory) log(kind ErrorKind) func(ctx context.Context, msg string, attrs ...logger.Attribute) {
+	if f.logger == nil {
+		return nil
+	}
+
+	level, ok := f.logLevels[kind]
+	if !ok {
+		level, ok = defaultLogLevels[kind]
+	}
+	if !ok {
+		level = LevelError
+	}
+
+	switch level {
+	case LevelDebug:
+		return f.logger.Debug
+	case LevelInfo:
+		return f.logger.Info
+	case LevelWarn:
+		return f.logger.Warn
+	}
+
+	return f.logger.Error
+}
@@ -0,0 +1,45 @@
+package errors
+
+import (
+	"errors"
+)
+
+// IsKind checks if err, or any error wrapped by it, is an Error of the
+// given kind.
+func IsKind(err error, kind ErrorKind) bool {
+	for err != nil {
+		var e *Error
+		if !errors.As(err, &e) {
+			return false
+		}
+		if e.Kind == kind {
+			return true
+		}
+
+		err = e.SublevelError
+	}
+
+	return false
+}
+
+// CodeOf gives the code of the first Error found inside err, or 0 if there
+// is none.
+func CodeOf(err error) int32 {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+
+	return 0
+}
+
+// KindOf gives the kind of the first Error found inside err, or an empty
+// kind if there is none.
+func KindOf(err error) ErrorKind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+
+	return ""
+}
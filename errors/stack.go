@@ -0,0 +1,36 @@
+package errors
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+const maxStackDepth = 32
+
+// captureStack gives the program counters of the current goroutine's call
+// stack, skipping the given number of frames (including captureStack).
+func captureStack(skip int) []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(skip+1, pcs)
+	return pcs[:n]
+}
+
+// formatStack formats a call stack with one function per line, followed by
+// its file and line.
+func formatStack(pcs []uintptr) string {
+	var (
+		b      strings.Builder
+		frames = runtime.CallersFrames(pcs)
+	)
+
+	for {
+		frame, more := frames.Next()
+		b.WriteString(fmt.Sprintf("%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line))
+		if !more {
+			break
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,101 @@
+package errors
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rsfreitas/go-pocket-utils/logger"
+)
+
+type suppressionKey struct {
+	kind        ErrorKind
+	destination string
+}
+
+type suppression struct {
+	until time.Time
+	count int64
+}
+
+// suppressionRegistry holds errors that should not be logged with their
+// default level during a known incident.
+type suppressionRegistry struct {
+	mu     sync.Mutex
+	rules  map[suppressionKey]*suppression
+	logger func(ctx context.Context, msg string, attrs ...logger.Attribute)
+}
+
+func newSuppressionRegistry(logger func(ctx context.Context, msg string, attrs ...logger.Attribute)) *suppressionRegistry {
+	return &suppressionRegistry{
+		rules:  make(map[suppressionKey]*suppression),
+		logger: logger,
+	}
+}
+
+func (r *suppressionRegistry) add(kind ErrorKind, destination string, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rules[suppressionKey{kind, destination}] = &suppression{
+		until: time.Now().Add(duration),
+	}
+}
+
+func (r *suppressionRegistry) remove(kind ErrorKind, destination string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.rules, suppressionKey{kind, destination})
+}
+
+func (r *suppressionRegistry) count(kind ErrorKind, destination string) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s, ok := r.rules[suppressionKey{kind, destination}]; ok {
+		return s.count
+	}
+
+	return 0
+}
+
+// suppress checks if an error must be suppressed, counting it if so. Rules
+// with an empty destination match every error of the same kind.
+func (r *suppressionRegistry) suppress(kind ErrorKind, destination string) bool {
+	if r == nil {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, key := range []suppressionKey{{kind, destination}, {kind, ""}} {
+		if s, ok := r.rules[key]; ok && now.Before(s.until) {
+			s.count++
+			return true
+		}
+	}
+
+	return false
+}
+
+// Suppress starts logging errors of a kind, sent to a destination, using
+// the Debug level for the specified duration, instead of their default
+// level. An empty destination matches all errors of the kind. It is
+// intended to be used during declared incidents, to avoid flooding the
+// output with known transient errors.
+func (f *Factory) Suppress(kind ErrorKind, destination string, duration time.Duration) {
+	f.suppressions.add(kind, destination, duration)
+}
+
+// Unsuppress removes a previously added suppression.
+func (f *Factory) Unsuppress(kind ErrorKind, destination string) {
+	f.suppressions.remove(kind, destination)
+}
+
+// SuppressedCount gives how many errors were suppressed by a suppression.
+func (f *Factory) SuppressedCount(kind ErrorKind, destination string) int64 {
+	return f.suppressions.count(kind, destination)
+}
@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"context"
+	"sync"
+)
+
+var (
+	carriedKeysMu sync.RWMutex
+	carriedKeys   []interface{}
+)
+
+// RegisterCarriedKeys registers context keys whose values are relevant for
+// logging, like a request ID or the values used by a ContextFieldExtractor,
+// so they can be kept by CarryContext.
+func RegisterCarriedKeys(keys ...interface{}) {
+	carriedKeysMu.Lock()
+	defer carriedKeysMu.Unlock()
+
+	carriedKeys = append(carriedKeys, keys...)
+}
+
+// CarryContext creates a new context, detached from ctx cancellation and
+// deadline, holding only the values of the keys registered with
+// RegisterCarriedKeys. It allows spawned goroutines to keep their log
+// correlation fields after the parent request context is canceled.
+func CarryContext(ctx context.Context) context.Context {
+	carriedKeysMu.RLock()
+	defer carriedKeysMu.RUnlock()
+
+	carried := context.Background()
+	for _, key := range carriedKeys {
+		if value := ctx.Value(key); value != nil {
+			carried = context.WithValue(carried, key, value)
+		}
+	}
+
+	return carried
+}
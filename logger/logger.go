@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
 	"time"
@@ -21,10 +22,16 @@ var levelNames = map[slog.Leveler]string{
 	levelFatal: "FATAL",
 }
 
+var placeholderRegex = regexp.MustCompile(`\{[^{}]+\}`)
+
 type (
 	// ContextFieldExtractor is a function that receives a context and should
 	// return a slice of loggerApi.Attribute to be added into every log call.
 	ContextFieldExtractor func(ctx context.Context) []Attribute
+
+	// ErrorHook is a function that receives every Error and Fatal message
+	// emitted by a Logger.
+	ErrorHook func(fatal bool, msg string, attrs []Attribute)
 )
 
 type Logger struct {
@@ -32,6 +39,8 @@ type Logger struct {
 	errorLogger    *slog.Logger
 	level          *logLeveler
 	fieldExtractor ContextFieldExtractor
+	errorHook      ErrorHook
+	exit           func(code int)
 }
 
 type Options struct {
@@ -39,6 +48,14 @@ type Options struct {
 	LogOnlyFatalLevel     bool
 	FixedAttributes       map[string]string
 	ContextFieldExtractor ContextFieldExtractor
+
+	// ErrorHook, when set, receives every Error and Fatal message, even the
+	// ones that are not printed due to the current log level.
+	ErrorHook ErrorHook
+
+	// Exit replaces os.Exit as the function called by Fatal, after the
+	// message is printed.
+	Exit func(code int)
 }
 
 // New creates a new Logger interface for applications.
@@ -98,11 +115,18 @@ func New(options Options) *Logger {
 		level.setLevel(levelFatal)
 	}
 
+	exit := options.Exit
+	if exit == nil {
+		exit = os.Exit
+	}
+
 	return &Logger{
 		logger:         slog.New(logHandler),
 		errorLogger:    slog.New(errHandler),
 		level:          level,
 		fieldExtractor: options.ContextFieldExtractor,
+		errorHook:      options.ErrorHook,
+		exit:           exit,
 	}
 }
 
@@ -135,6 +159,10 @@ func (l *Logger) error(ctx context.Context, msg string, attrs ...Attribute) {
 		pcs     [1]uintptr
 	)
 
+	if l.errorHook != nil {
+		l.errorHook(false, msg, attrs)
+	}
+
 	if l.level.Level() > slog.LevelError {
 		return
 	}
@@ -151,9 +179,13 @@ func (l *Logger) error(ctx context.Context, msg string, attrs ...Attribute) {
 
 // Fatal outputs message using fatal level.
 func (l *Logger) Fatal(ctx context.Context, msg string, attrs ...Attribute) {
+	if l.errorHook != nil {
+		l.errorHook(true, msg, attrs)
+	}
+
 	mFields := l.mergeFieldsWithCtx(ctx, attrs)
 	l.logger.Log(ctx, levelFatal, msg, mFields...)
-	os.Exit(fatalExitCode)
+	l.exit(fatalExitCode)
 }
 
 func (l *Logger) mergeFieldsWithCtx(ctx context.Context, attrs []Attribute) []any {
@@ -279,3 +311,54 @@ func (l *Logger) Fatalf(ctx context.Context, msg string, attrs ...map[string]int
 
 	l.Fatal(ctx, msg, loggerFields...)
 }
+
+// Debugt outputs messages using debug level, replacing named placeholders,
+// like {user_id}, with their respective attribute values.
+func (l *Logger) Debugt(ctx context.Context, msg string, attrs ...Attribute) {
+	l.Debug(ctx, fillPlaceholders(msg, attrs), attrs...)
+}
+
+// Infot outputs messages using info level, replacing named placeholders,
+// like {user_id}, with their respective attribute values.
+func (l *Logger) Infot(ctx context.Context, msg string, attrs ...Attribute) {
+	l.Info(ctx, fillPlaceholders(msg, attrs), attrs...)
+}
+
+// Warnt outputs messages using warning level, replacing named placeholders,
+// like {user_id}, with their respective attribute values.
+func (l *Logger) Warnt(ctx context.Context, msg string, attrs ...Attribute) {
+	l.Warn(ctx, fillPlaceholders(msg, attrs), attrs...)
+}
+
+// Errort outputs messages using error level, replacing named placeholders,
+// like {user_id}, with their respective attribute values.
+func (l *Logger) Errort(ctx context.Context, msg string, attrs ...Attribute) {
+	l.error(ctx, fillPlaceholders(msg, attrs), attrs...)
+}
+
+// Fatalt outputs messages using fatal level, replacing named placeholders,
+// like {user_id}, with their respective attribute values.
+func (l *Logger) Fatalt(ctx context.Context, msg string, attrs ...Attribute) {
+	l.Fatal(ctx, fillPlaceholders(msg, attrs), attrs...)
+}
+
+// fillPlaceholders replaces every {key} inside msg with the value of the
+// attribute with the same key. Placeholders without a matching attribute
+// are kept untouched. The attributes are still added as structured fields
+// into the message.
+func fillPlaceholders(msg string, attrs []Attribute) string {
+	if len(attrs) == 0 || !strings.Contains(msg, "{") {
+		return msg
+	}
+
+	return placeholderRegex.ReplaceAllStringFunc(msg, func(placeholder string) string {
+		key := placeholder[1 : len(placeholder)-1]
+		for _, attr := range attrs {
+			if attr.Key() == key {
+				return fmt.Sprint(attr.Value())
+			}
+		}
+
+		return placeholder
+	})
+}
@@ -0,0 +1,96 @@
+// Package loggertest provides a Logger for tests that fails them when
+// errors are logged unexpectedly.
+package loggertest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rsfreitas/go-pocket-utils/logger"
+)
+
+// TestLogger is a Logger for integration tests that fails the test when
+// Error or Fatal messages are emitted unexpectedly, catching silent error
+// paths.
+type TestLogger struct {
+	*logger.Logger
+
+	tb       testing.TB
+	mu       sync.Mutex
+	expected map[string]int
+}
+
+// New creates a new TestLogger bound to tb. Every Error message must be
+// previously registered with ExpectError, otherwise the test fails. Fatal
+// messages always fail the test, since they would terminate the application,
+// but the test keeps running, since they are usually logged outside the test
+// goroutine. The ErrorHook and Exit options are replaced by the TestLogger.
+func New(tb testing.TB, options logger.Options) *TestLogger {
+	t := &TestLogger{
+		tb:       tb,
+		expected: make(map[string]int),
+	}
+
+	options.ErrorHook = t.check
+	options.Exit = func(code int) {
+		// Fatal messages are already reported by check, and FailNow can
+		// only be called from the test goroutine.
+	}
+
+	t.Logger = logger.New(options)
+	tb.Cleanup(t.checkExpectations)
+	return t
+}
+
+// ExpectError registers that an Error message is expected to be emitted
+// during the test. It can be called more than once for the same message to
+// expect it multiple times.
+func (t *TestLogger) ExpectError(msg string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.expected[msg]++
+}
+
+func (t *TestLogger) check(fatal bool, msg string, attrs []logger.Attribute) {
+	t.tb.Helper()
+
+	if fatal {
+		t.tb.Errorf("unexpected fatal log message: '%s'%s", msg, formatAttributes(attrs))
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.expected[msg] > 0 {
+		t.expected[msg]--
+		return
+	}
+
+	t.tb.Errorf("unexpected error log message: '%s'%s", msg, formatAttributes(attrs))
+}
+
+// checkExpectations fails the test if some expected message was not
+// emitted.
+func (t *TestLogger) checkExpectations() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for msg, count := range t.expected {
+		if count > 0 {
+			t.tb.Errorf("expected error log message '%s' was not emitted (%d remaining)", msg, count)
+		}
+	}
+}
+
+func formatAttributes(attrs []logger.Attribute) string {
+	var s strings.Builder
+	for _, attr := range attrs {
+		s.WriteString(fmt.Sprintf(" %s=%v", attr.Key(), attr.Value()))
+	}
+
+	return s.String()
+}
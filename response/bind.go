@@ -0,0 +1,141 @@
+package response
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/valyala/fasthttp"
+)
+
+const bindErrorMsg = "request validation failed"
+
+// bindLocations are the struct tags supported by the binder, which are also
+// the location of fields inside BindError.
+var bindLocations = []string{"path", "query", "header"}
+
+// BindError holds all fields that could not be bound from the request.
+// When forwarded with ForwardError, it is sent as a bad request with the
+// fields.
+type BindError struct {
+	Fields []*Field
+}
+
+func (e *BindError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = fmt.Sprintf("%s@%s: %s", f.Field, f.Location, f.Message)
+	}
+
+	return strings.Join(msgs, ", ")
+}
+
+// binder gives the values of a request by location and name.
+type binder func(location, name string) (string, bool)
+
+// BindFasthttp populates out, a pointer to a struct, with the request path
+// parameters, query parameters and headers named by the path, query and
+// header struct tags of its fields.
+func BindFasthttp(ctx *fasthttp.RequestCtx, out interface{}) error {
+	return bind(out, func(location, name string) (string, bool) {
+		switch location {
+		case "path":
+			v := ctx.UserValue(name)
+			if v == nil {
+				return "", false
+			}
+			return fmt.Sprint(v), true
+
+		case "query":
+			values := ctx.QueryArgs().PeekMulti(name)
+			if len(values) == 0 {
+				return "", false
+			}
+			s := make([]string, len(values))
+			for i, v := range values {
+				s[i] = string(v)
+			}
+			return strings.Join(s, ","), true
+
+		case "header":
+			v := ctx.Request.Header.Peek(name)
+			return string(v), v != nil
+		}
+
+		return "", false
+	})
+}
+
+// BindEcho populates out, a pointer to a struct, with the request path
+// parameters, query parameters and headers named by the path, query and
+// header struct tags of its fields.
+func BindEcho(ctx echo.Context, out interface{}) error {
+	return bind(out, func(location, name string) (string, bool) {
+		switch location {
+		case "path":
+			for _, n := range ctx.ParamNames() {
+				if n == name {
+					return ctx.Param(name), true
+				}
+			}
+
+		case "query":
+			values, ok := ctx.QueryParams()[name]
+			return strings.Join(values, ","), ok
+
+		case "header":
+			values, ok := ctx.Request().Header[http.CanonicalHeaderKey(name)]
+			return strings.Join(values, ","), ok
+		}
+
+		return "", false
+	})
+}
+
+func bind(out interface{}, values binder) error {
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("bind target must be a pointer to a struct")
+	}
+
+	var (
+		rs     = rv.Elem()
+		fields []*Field
+	)
+
+	for i := 0; i < rs.NumField(); i++ {
+		sf := rs.Type().Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		for _, location := range bindLocations {
+			name, ok := sf.Tag.Lookup(location)
+			if !ok || name == "" || name == "-" {
+				continue
+			}
+
+			value, ok := values(location, name)
+			if !ok {
+				continue
+			}
+
+			if err := Decode([]byte(value), rs.Field(i).Addr().Interface()); err != nil {
+				fields = append(fields, &Field{
+					Field:    name,
+					Message:  fmt.Sprintf("invalid value '%s'", value),
+					Location: location,
+				})
+			}
+		}
+	}
+
+	if len(fields) > 0 {
+		return &BindError{Fields: fields}
+	}
+
+	return nil
+}
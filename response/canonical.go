@@ -0,0 +1,28 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// canonicalJSON re-encodes a JSON document with all object keys sorted and
+// without insignificant whitespaces, making it byte-stable. Numbers are
+// kept as they were to avoid losing precision. Invalid JSON documents are
+// returned untouched.
+func canonicalJSON(data []byte) ([]byte, error) {
+	if !json.Valid(data) {
+		return data, nil
+	}
+
+	var (
+		v   interface{}
+		dec = json.NewDecoder(bytes.NewReader(data))
+	)
+
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(v)
+}
@@ -0,0 +1,163 @@
+package response
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/labstack/echo/v4"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	encodingGzip   = "gzip"
+	encodingBrotli = "br"
+)
+
+// CompressionOptions enables compressing response bodies with gzip or
+// brotli, negotiated through the Accept-Encoding request header.
+type CompressionOptions struct {
+	// MinSize is the minimum body size, in bytes, to be compressed.
+	MinSize int
+
+	// ExcludedContentTypes holds media types that must never be compressed,
+	// like already compressed images. Entries like "image/*" match all
+	// subtypes.
+	ExcludedContentTypes []string
+}
+
+// compress compresses out using the best encoding accepted by the client,
+// giving the encoding used or an empty string if out was kept untouched.
+func (r *Response) compress(contentType string, out []byte) ([]byte, string) {
+	if r.compression == nil || len(out) < r.compression.MinSize || r.excludedFromCompression(contentType) {
+		return out, ""
+	}
+
+	switch negotiateEncoding(r.requestHeader("Accept-Encoding")) {
+	case encodingBrotli:
+		return fasthttp.AppendBrotliBytes(nil, out), encodingBrotli
+	case encodingGzip:
+		return fasthttp.AppendGzipBytes(nil, out), encodingGzip
+	}
+
+	return out, ""
+}
+
+func (r *Response) excludedFromCompression(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+
+	for _, excluded := range r.compression.ExcludedContentTypes {
+		if excluded == mediaType {
+			return true
+		}
+
+		if prefix, ok := strings.CutSuffix(excluded, "*"); ok && strings.HasPrefix(mediaType, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// negotiateEncoding chooses, from an Accept-Encoding header, the supported
+// encoding with the highest quality value, preferring brotli on ties.
+func negotiateEncoding(header string) string {
+	var (
+		best     string
+		quality  float64
+		accepted = parseAcceptEncoding(header)
+	)
+
+	for _, name := range []string{encodingBrotli, encodingGzip} {
+		if q := encodingQuality(accepted, name); q > quality {
+			best, quality = name, q
+		}
+	}
+
+	return best
+}
+
+// encodingQuality gives the quality value of an encoding. "*" only applies
+// to encodings not listed by the client.
+func encodingQuality(accepted map[string]float64, name string) float64 {
+	if q, ok := accepted[name]; ok {
+		return q
+	}
+
+	return accepted["*"]
+}
+
+// parseAcceptEncoding gives the encodings listed by the client with their
+// quality values, including the ones not acceptable (q=0).
+func parseAcceptEncoding(header string) map[string]float64 {
+	encodings := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+
+		encodings[name] = q
+	}
+
+	return encodings
+}
+
+// streamWithCompression is like stream, but gzip compresses the body when
+// compression is enabled and accepted by the client. Streams are only
+// compressed with gzip.
+func (r *Response) streamWithCompression(contentType string, headers map[string]string, fn func(w io.Writer, done <-chan struct{}) error) error {
+	accepted := parseAcceptEncoding(r.requestHeader("Accept-Encoding"))
+	if r.compression == nil || r.excludedFromCompression(contentType) || encodingQuality(accepted, encodingGzip) == 0 {
+		return r.stream(contentType, headers, fn)
+	}
+
+	h := map[string]string{
+		"Content-Encoding": encodingGzip,
+		"Vary":             "Accept-Encoding",
+	}
+	for k, v := range headers {
+		h[k] = v
+	}
+
+	return r.stream(contentType, h, func(w io.Writer, done <-chan struct{}) error {
+		gz := gzip.NewWriter(w)
+		err := fn(&flushWriter{w: gz, flush: gz.Flush}, done)
+		if cerr := gz.Close(); err == nil {
+			err = cerr
+		}
+
+		return err
+	})
+}
+
+// requestHeader gives the value of a header of the request.
+func (r *Response) requestHeader(key string) string {
+	if fctx, ok := r.ctx.(*fasthttp.RequestCtx); ok {
+		return string(fctx.Request.Header.Peek(key))
+	}
+
+	if ectx, ok := r.ctx.(echo.Context); ok {
+		return ectx.Request().Header.Get(key)
+	}
+
+	if gctx, ok := r.ctx.(*gin.Context); ok {
+		return gctx.GetHeader(key)
+	}
+
+	return ""
+}
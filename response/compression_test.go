@@ -0,0 +1,31 @@
+package response
+
+import (
+	"testing"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", encodingGzip},
+		{"br", encodingBrotli},
+		{"gzip, br", encodingBrotli},
+		{"gzip;q=1.0, br;q=0.5", encodingGzip},
+		{"br;q=0, gzip", encodingGzip},
+		{"*", encodingBrotli},
+		{"br;q=0, *", encodingGzip},
+		{"br;q=0, gzip;q=0, *", ""},
+		{"gzip;q=0.2, *;q=0.5", encodingBrotli},
+		{"*;q=0", ""},
+	}
+
+	for _, tt := range tests {
+		if got := negotiateEncoding(tt.header); got != tt.want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
@@ -1,11 +1,15 @@
 package response
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 var (
@@ -134,13 +138,142 @@ func Decode(value []byte, out any) error {
 		}
 		*v = f
 
+	case *[]int:
+		if v == nil {
+			return decodeError
+		}
+
+		p, err := parseList(value, func(s string) (int, error) {
+			i, err := strconv.ParseInt(s, 10, 64)
+			return int(i), err
+		})
+		if err != nil {
+			return err
+		}
+		*v = p
+
+	case *[]int64:
+		if v == nil {
+			return decodeError
+		}
+
+		p, err := parseList(value, func(s string) (int64, error) {
+			return strconv.ParseInt(s, 10, 64)
+		})
+		if err != nil {
+			return err
+		}
+		*v = p
+
+	case *[]float64:
+		if v == nil {
+			return decodeError
+		}
+
+		p, err := parseList(value, func(s string) (float64, error) {
+			return strconv.ParseFloat(s, 64)
+		})
+		if err != nil {
+			return err
+		}
+		*v = p
+
+	case *time.Time:
+		if v == nil {
+			return decodeError
+		}
+
+		t, err := parseTime(string(value))
+		if err != nil {
+			return err
+		}
+		*v = t
+
+	case *time.Duration:
+		if v == nil {
+			return decodeError
+		}
+
+		d, err := time.ParseDuration(string(value))
+		if err != nil {
+			return err
+		}
+		*v = d
+
+	case *uuid.UUID:
+		if v == nil {
+			return decodeError
+		}
+
+		id, err := uuid.ParseBytes(value)
+		if err != nil {
+			return err
+		}
+		*v = id
+
+	case *map[string]string:
+		if v == nil {
+			return decodeError
+		}
+
+		// Maps are expected as comma separated key=value pairs.
+		m := make(map[string]string)
+		for _, pair := range strings.Split(string(value), ",") {
+			if pair == "" {
+				continue
+			}
+
+			key, val, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("invalid map entry '%s'", pair)
+			}
+			m[strings.TrimSpace(key)] = strings.TrimSpace(val)
+		}
+		*v = m
+
 	default:
-		return errors.New("unsupported type conversion")
+		// Other pointers are decoded as JSON.
+		rv := reflect.ValueOf(out)
+		if rv.Kind() != reflect.Pointer {
+			return errors.New("unsupported type conversion")
+		}
+		if rv.IsNil() {
+			return decodeError
+		}
+
+		return json.Unmarshal(value, out)
 	}
 
 	return nil
 }
 
+// parseList converts a comma separated list using parse for every item.
+func parseList[T any](value []byte, parse func(s string) (T, error)) ([]T, error) {
+	var (
+		items = strings.Split(string(value), ",")
+		out   = make([]T, 0, len(items))
+	)
+
+	for _, item := range items {
+		v, err := parse(strings.TrimSpace(item))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+
+	return out, nil
+}
+
+// parseTime accepts both RFC3339 timestamps and unix seconds.
+func parseTime(s string) (time.Time, error) {
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(sec, 0).UTC(), nil
+	}
+
+	return time.Parse(time.RFC3339, s)
+}
+
 // Zero clears the value of the argument to its zero value according its
 // type.
 func Zero(value interface{}) {
@@ -0,0 +1,181 @@
+package response
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	MediaTypeJSON     = "application/json"
+	MediaTypeXML      = "application/xml"
+	MediaTypeProtobuf = "application/x-protobuf"
+	MediaTypeMsgpack  = "application/msgpack"
+)
+
+// Encoder is a function that converts a response payload into the bytes of
+// a specific media type.
+type Encoder func(data interface{}) ([]byte, error)
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[string]Encoder{
+		MediaTypeJSON:     json.Marshal,
+		MediaTypeXML:      xml.Marshal,
+		MediaTypeProtobuf: encodeProtobuf,
+		MediaTypeMsgpack:  msgpack.Marshal,
+	}
+)
+
+// RegisterEncoder adds (or replaces) the Encoder used for responses of a
+// media type, allowing it to be negotiated through the Accept header.
+func RegisterEncoder(mediaType string, encoder Encoder) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+
+	encoders[mediaType] = encoder
+}
+
+func encoderFor(mediaType string) (Encoder, bool) {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+
+	e, ok := encoders[mediaType]
+	return e, ok
+}
+
+func encodeProtobuf(data interface{}) ([]byte, error) {
+	m, ok := data.(proto.Message)
+	if !ok {
+		return nil, errors.New("response data is not a protobuf message")
+	}
+
+	return proto.Marshal(m)
+}
+
+// rawJSON is a payload already encoded as JSON by the handler, which is
+// sent without being encoded again.
+type rawJSON []byte
+
+// encode converts data into the response body, using the media type
+// negotiated with the client. rawJSON payloads are handled as already
+// encoded content. When the negotiated encoder cannot handle data, JSON is
+// used.
+func (r *Response) encode(data interface{}) ([]byte, string, error) {
+	if raw, ok := data.(rawJSON); ok {
+		out, err := r.canonicalize(raw)
+		if err != nil {
+			return nil, "", err
+		}
+
+		return out, r.contentType, nil
+	}
+
+	mediaType, contentType := r.negotiate()
+	if encoder, ok := encoderFor(mediaType); ok && mediaType != MediaTypeJSON {
+		if out, err := encoder(data); err == nil {
+			return out, contentType, nil
+		}
+
+		contentType = MediaTypeJSON
+	}
+
+	out, err := json.Marshal(data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	out, err = r.canonicalize(out)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return out, contentType, nil
+}
+
+// canonicalize gives the canonical form of a JSON body when the
+// CanonicalJSON option is enabled.
+func (r *Response) canonicalize(out []byte) ([]byte, error) {
+	if !r.canonical {
+		return out, nil
+	}
+
+	return canonicalJSON(out)
+}
+
+// negotiate chooses the response media type (and its content type header
+// value) from the request Accept header. When the client accepts anything,
+// the configured content type is used.
+func (r *Response) negotiate() (string, string) {
+	for _, accepted := range parseAccept(r.acceptHeader()) {
+		if accepted == "*/*" {
+			break
+		}
+
+		if _, ok := encoderFor(accepted); ok {
+			return accepted, accepted
+		}
+	}
+
+	if r.contentType == "" {
+		return MediaTypeJSON, MediaTypeJSON
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.contentType)
+	if err != nil {
+		return MediaTypeJSON, r.contentType
+	}
+
+	return mediaType, r.contentType
+}
+
+func (r *Response) acceptHeader() string {
+	return r.requestHeader("Accept")
+}
+
+// parseAccept gives the media types of an Accept header sorted by their
+// quality values.
+func parseAccept(header string) []string {
+	type accepted struct {
+		mediaType string
+		quality   float64
+	}
+
+	var types []accepted
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = v
+			}
+		}
+
+		if quality > 0 {
+			types = append(types, accepted{mediaType, quality})
+		}
+	}
+
+	sort.SliceStable(types, func(i, j int) bool {
+		return types[i].quality > types[j].quality
+	})
+
+	out := make([]string, len(types))
+	for i, t := range types {
+		out[i] = t.mediaType
+	}
+
+	return out
+}
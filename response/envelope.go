@@ -0,0 +1,71 @@
+package response
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EnvelopeOptions wraps all ForwardSuccess payloads inside a standard
+// envelope, like {"data": ..., "meta": {...}}.
+type EnvelopeOptions struct {
+	// MetaHooks add fields into the meta object of every envelope. It is
+	// omitted when no hook adds a field.
+	MetaHooks []MetaHook
+}
+
+// MetaHook adds fields into the meta object of a success envelope.
+type MetaHook func(r *Response, meta map[string]interface{})
+
+type successEnvelope struct {
+	Data interface{}            `json:"data"`
+	Meta map[string]interface{} `json:"meta,omitempty"`
+}
+
+// RequestIDMeta adds the value of a request header, usually carrying the
+// request ID, into the meta object as request_id.
+func RequestIDMeta(header string) MetaHook {
+	return func(r *Response, meta map[string]interface{}) {
+		if id := r.requestHeader(header); id != "" {
+			meta["request_id"] = id
+		}
+	}
+}
+
+// TimingMeta adds the time spent handling the request, in milliseconds,
+// into the meta object as duration_ms. It is only available when the
+// request is handled by EchoMiddleware or WrapFasthttp.
+func TimingMeta() MetaHook {
+	return func(r *Response, meta map[string]interface{}) {
+		if !r.startedAt.IsZero() {
+			meta["duration_ms"] = float64(time.Since(r.startedAt).Microseconds()) / 1000
+		}
+	}
+}
+
+// wrapSuccess puts data inside the success envelope, if enabled.
+func (r *Response) wrapSuccess(data interface{}) interface{} {
+	if r.envelope == nil {
+		return data
+	}
+
+	meta := make(map[string]interface{})
+	for _, hook := range r.envelope.MetaHooks {
+		hook(r, meta)
+	}
+
+	return &successEnvelope{
+		Data: data,
+		Meta: meta,
+	}
+}
+
+// wrapSuccessBytes is like wrapSuccess, but for payloads already encoded
+// as JSON by the handler, which are sent as they are when the envelope is
+// disabled.
+func (r *Response) wrapSuccessBytes(b []byte) interface{} {
+	if r.envelope == nil {
+		return rawJSON(b)
+	}
+
+	return r.wrapSuccess(json.RawMessage(b))
+}
@@ -13,12 +13,15 @@ const (
 )
 
 type responseError struct {
-	Code        int      `json:"code,omitempty"`
-	Source      string   `json:"source,omitempty"`
-	Message     string   `json:"message,omitempty"`
-	Details     string   `json:"details,omitempty"`
-	Destination string   `json:"destination,omitempty"`
-	Fields      []*Field `json:"fields,omitempty"`
+	Code        int               `json:"code,omitempty"`
+	Source      string            `json:"source,omitempty"`
+	Message     string            `json:"message,omitempty"`
+	Details     string            `json:"details,omitempty"`
+	Destination string            `json:"destination,omitempty"`
+	Fields      []*Field          `json:"fields,omitempty"`
+	Retryable   bool              `json:"retryable,omitempty"`
+	RetryAfter  int64             `json:"retry_after,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
 }
 
 type Field struct {
@@ -28,12 +31,15 @@ type Field struct {
 }
 
 type responseErrorOptions struct {
-	Code        int      `json:"code,omitempty"`
-	Source      string   `json:"source,omitempty"`
-	Message     string   `json:"message,omitempty"`
-	Details     string   `json:"details,omitempty"`
-	Destination string   `json:"destination,omitempty"`
-	Fields      []*Field `json:"fields,omitempty"`
+	Code        int               `json:"code,omitempty"`
+	Source      string            `json:"source,omitempty"`
+	Message     string            `json:"message,omitempty"`
+	Details     string            `json:"details,omitempty"`
+	Destination string            `json:"destination,omitempty"`
+	Fields      []*Field          `json:"fields,omitempty"`
+	Retryable   bool              `json:"retryable,omitempty"`
+	RetryAfter  int64             `json:"retry_after,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
 }
 
 func newResponseError(options *responseErrorOptions) *responseError {
@@ -44,6 +50,9 @@ func newResponseError(options *responseErrorOptions) *responseError {
 		Details:     options.Details,
 		Destination: options.Destination,
 		Fields:      options.Fields,
+		Retryable:   options.Retryable,
+		RetryAfter:  options.RetryAfter,
+		Metadata:    options.Metadata,
 	}
 }
 
@@ -0,0 +1,93 @@
+package response
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/labstack/echo/v4"
+	"github.com/valyala/fasthttp"
+)
+
+// SetLastModified sets the Last-Modified header of the response, allowing
+// requests with If-Modified-Since to be answered with 304 Not Modified.
+func (r *Response) SetLastModified(t time.Time) {
+	r.SetHeader("Last-Modified", t.UTC().Format(http.TimeFormat))
+}
+
+// notModified checks the conditional headers of GET and HEAD requests
+// against the ETag and Last-Modified of a successful response. When
+// Options.ETag is enabled and the handler has not set an ETag, one is
+// computed from the body.
+func (r *Response) notModified(statusCode int, out []byte) bool {
+	if statusCode != http.StatusOK {
+		return false
+	}
+
+	etag := r.headers.Get("ETag")
+	if etag == "" && r.etag {
+		etag = bodyETag(out)
+		r.SetHeader("ETag", etag)
+	}
+
+	method := r.requestMethod()
+	if method != http.MethodGet && method != http.MethodHead {
+		return false
+	}
+
+	// If-Modified-Since is ignored when If-None-Match is sent.
+	if match := r.requestHeader("If-None-Match"); match != "" {
+		return etag != "" && etagMatches(match, etag)
+	}
+
+	lastModified, err := http.ParseTime(r.headers.Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+
+	since, err := http.ParseTime(r.requestHeader("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// bodyETag gives a weak ETag of a body. It is weak because the same body
+// may be sent with different encodings.
+func bodyETag(out []byte) string {
+	sum := sha256.Sum256(out)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches tells if etag is one of the values of an If-None-Match
+// header, using the weak comparison.
+func etagMatches(header, etag string) bool {
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.TrimPrefix(v, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Response) requestMethod() string {
+	if fctx, ok := r.ctx.(*fasthttp.RequestCtx); ok {
+		return string(fctx.Method())
+	}
+
+	if ectx, ok := r.ctx.(echo.Context); ok {
+		return ectx.Request().Method
+	}
+
+	if gctx, ok := r.ctx.(*gin.Context); ok {
+		return gctx.Request.Method
+	}
+
+	return ""
+}
@@ -0,0 +1,164 @@
+package response
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"reflect"
+)
+
+const (
+	MediaTypeCSV    = "text/csv"
+	MediaTypeNDJSON = "application/x-ndjson"
+)
+
+// CSVOptions controls how ForwardCSV writes rows.
+type CSVOptions struct {
+	// Header is the first line of the file. It is required when rows are
+	// string slices, since struct rows build it from their fields, using
+	// the csv struct tag (or the field name) of each one. Fields tagged
+	// with csv:"-" are skipped.
+	Header []string
+
+	// Filename, when set, sends the file as an attachment with this name.
+	Filename string
+
+	// Comma is the field delimiter, ',' by default.
+	Comma rune
+}
+
+// ForwardCSV streams rows, a slice of structs (or pointers to them) or a
+// slice of string slices, as a CSV file. The body is gzip compressed when
+// Options.Compression is enabled and the client accepts it.
+func (r *Response) ForwardCSV(rows interface{}, opts CSVOptions) error {
+	rv := reflect.ValueOf(rows)
+	if rv.Kind() != reflect.Slice {
+		return errors.New("csv rows must be a slice")
+	}
+	if opts.Header == nil && rv.Type().Elem() == reflect.TypeOf([]string(nil)) {
+		return errors.New("csv header is required for string slice rows")
+	}
+
+	headers := map[string]string{}
+	if opts.Filename != "" {
+		headers["Content-Disposition"] = mime.FormatMediaType("attachment", map[string]string{"filename": opts.Filename})
+	}
+
+	return r.streamWithCompression(MediaTypeCSV+"; charset=utf-8", headers, func(w io.Writer, _ <-chan struct{}) error {
+		cw := csv.NewWriter(w)
+		if opts.Comma != 0 {
+			cw.Comma = opts.Comma
+		}
+
+		header := opts.Header
+		for i := 0; i < rv.Len(); i++ {
+			record, fields, err := csvRecord(rv.Index(i))
+			if err != nil {
+				return err
+			}
+
+			if i == 0 {
+				if header == nil {
+					if fields == nil {
+						return errors.New("csv header is required for string slice rows")
+					}
+					header = fields
+				}
+				if err := writeCSVHeader(cw, header); err != nil {
+					return err
+				}
+			}
+
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+
+		if rv.Len() == 0 {
+			if err := writeCSVHeader(cw, header); err != nil {
+				return err
+			}
+		}
+
+		cw.Flush()
+		return cw.Error()
+	})
+}
+
+// ForwardNDJSON streams every item received from items as a line of
+// newline delimited JSON, until items is closed or the client disconnects.
+// The body is gzip compressed when Options.Compression is enabled and the
+// client accepts it.
+func (r *Response) ForwardNDJSON(items <-chan interface{}) error {
+	return r.streamWithCompression(MediaTypeNDJSON, nil, func(w io.Writer, done <-chan struct{}) error {
+		enc := json.NewEncoder(w)
+		for {
+			select {
+			case <-done:
+				return nil
+
+			case item, ok := <-items:
+				if !ok {
+					return nil
+				}
+
+				if err := enc.Encode(item); err != nil {
+					return err
+				}
+			}
+		}
+	})
+}
+
+func writeCSVHeader(cw *csv.Writer, header []string) error {
+	if header == nil {
+		return nil
+	}
+
+	return cw.Write(header)
+}
+
+// csvRecord gives the values of a row and, for struct rows, the name of
+// its columns.
+func csvRecord(row reflect.Value) ([]string, []string, error) {
+	for row.Kind() == reflect.Pointer || row.Kind() == reflect.Interface {
+		row = row.Elem()
+	}
+	if !row.IsValid() {
+		return nil, nil, errors.New("csv rows cannot be nil")
+	}
+
+	switch row.Kind() {
+	case reflect.Slice:
+		if s, ok := row.Interface().([]string); ok {
+			return s, nil, nil
+		}
+
+	case reflect.Struct:
+		var (
+			record []string
+			fields []string
+		)
+
+		for i := 0; i < row.NumField(); i++ {
+			sf := row.Type().Field(i)
+			name := sf.Tag.Get("csv")
+			if !sf.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = sf.Name
+			}
+
+			fields = append(fields, name)
+			record = append(record, fmt.Sprint(row.Field(i).Interface()))
+		}
+
+		return record, fields, nil
+	}
+
+	return nil, nil, fmt.Errorf("unsupported csv row type '%s'", row.Type())
+}
@@ -0,0 +1,175 @@
+package response
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/labstack/echo/v4"
+	"github.com/valyala/fasthttp"
+)
+
+// sniffLength is the amount of bytes used to detect a content type.
+const sniffLength = 512
+
+// FileOptions holds options to send a file with ForwardFile.
+type FileOptions struct {
+	// ContentType is the file content type. If empty, it is detected from
+	// the filename extension or from the file content.
+	ContentType string
+
+	// Size is the file size, used as Content-Length. If zero, it is only
+	// known when the reader is an io.Seeker.
+	Size int64
+
+	// Inline asks the client to display the file instead of downloading
+	// it.
+	Inline bool
+}
+
+// ForwardFile sends the content of r as a file called filename. When r is
+// an io.ReadSeeker, range requests are supported.
+func (r *Response) ForwardFile(filename string, reader io.Reader, opts FileOptions) error {
+	size := opts.Size
+	seeker, seekable := reader.(io.ReadSeeker)
+	if size == 0 && seekable {
+		end, err := seeker.Seek(0, io.SeekEnd)
+		if err != nil {
+			return err
+		}
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		size = end
+	}
+
+	contentType := opts.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(filename))
+	}
+	if contentType == "" {
+		head := make([]byte, sniffLength)
+		n, err := io.ReadFull(reader, head)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+
+		contentType = http.DetectContentType(head[:n])
+		if seekable {
+			if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+		} else {
+			reader = io.MultiReader(bytes.NewReader(head[:n]), reader)
+		}
+	}
+
+	disposition := "attachment"
+	if opts.Inline {
+		disposition = "inline"
+	}
+	disposition = mime.FormatMediaType(disposition, map[string]string{"filename": filename})
+
+	if fctx, ok := r.ctx.(*fasthttp.RequestCtx); ok {
+		return r.forwardFasthttpFile(fctx, reader, size, contentType, disposition)
+	}
+
+	var (
+		w   http.ResponseWriter
+		req *http.Request
+	)
+
+	if ectx, ok := r.ctx.(echo.Context); ok {
+		w, req = ectx.Response(), ectx.Request()
+	}
+	if gctx, ok := r.ctx.(*gin.Context); ok {
+		w, req = gctx.Writer, gctx.Request
+	}
+	if w == nil {
+		return nil
+	}
+
+	statusCode := r.responseCode(http.StatusOK)
+	r.writeHeaders(statusCode)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", disposition)
+
+	rec := &statusRecorder{ResponseWriter: w, statusCode: statusCode}
+	defer func() {
+		r.intercept(rec.statusCode, rec.size)
+	}()
+
+	if seekable {
+		// ServeContent already handles range requests.
+		http.ServeContent(rec, req, filename, time.Time{}, seeker)
+		return nil
+	}
+
+	if size > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	}
+
+	rec.WriteHeader(statusCode)
+	_, err := io.Copy(rec, reader)
+	return err
+}
+
+func (r *Response) forwardFasthttpFile(ctx *fasthttp.RequestCtx, reader io.Reader, size int64, contentType, disposition string) error {
+	statusCode := r.responseCode(fasthttp.StatusOK)
+	bodySize := int(size)
+	if size == 0 {
+		bodySize = -1
+	}
+
+	r.writeHeaders(statusCode)
+	ctx.Response.Header.SetContentType(contentType)
+	ctx.Response.Header.Set("Content-Disposition", disposition)
+
+	if seeker, ok := reader.(io.ReadSeeker); ok && size > 0 {
+		ctx.Response.Header.Set(fasthttp.HeaderAcceptRanges, "bytes")
+
+		if byteRange := ctx.Request.Header.Peek(fasthttp.HeaderRange); len(byteRange) > 0 {
+			start, end, err := fasthttp.ParseByteRange(byteRange, int(size))
+			if err != nil {
+				ctx.Response.Header.Set(fasthttp.HeaderContentRange, "bytes */"+strconv.FormatInt(size, 10))
+				ctx.Response.SetStatusCode(fasthttp.StatusRequestedRangeNotSatisfiable)
+				r.intercept(fasthttp.StatusRequestedRangeNotSatisfiable, 0)
+				return nil
+			}
+
+			if _, err := seeker.Seek(int64(start), io.SeekStart); err != nil {
+				return err
+			}
+
+			ctx.Response.Header.SetContentRange(start, end, int(size))
+			ctx.Response.SetStatusCode(fasthttp.StatusPartialContent)
+			ctx.Response.SetBodyStream(r.countBody(fasthttp.StatusPartialContent, io.LimitReader(seeker, int64(end-start+1))), end-start+1)
+			return nil
+		}
+	}
+
+	ctx.Response.SetStatusCode(statusCode)
+	ctx.Response.SetBodyStream(r.countBody(statusCode, reader), bodySize)
+	return nil
+}
+
+// countBody wraps a fasthttp body stream so interceptors are called once
+// it is sent.
+func (r *Response) countBody(statusCode int, reader io.Reader) io.Reader {
+	if len(r.onResponse) == 0 {
+		return reader
+	}
+
+	return &bodyCounter{
+		Reader: reader,
+		done: func(size int) {
+			r.intercept(statusCode, size)
+		},
+	}
+}
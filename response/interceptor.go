@@ -0,0 +1,99 @@
+package response
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+// ResponseEvent describes a response sent, being given to every interceptor
+// set in Options.Interceptors.
+type ResponseEvent struct {
+	Method     string
+	Path       string
+	StatusCode int
+
+	// Size is the number of bytes of the body sent.
+	Size int
+
+	// ErrorKind is the kind of the service error forwarded, if any.
+	ErrorKind string
+
+	// ErrorAttributes are the attributes embedded into the service error
+	// forwarded, if any. They are never sent to the client.
+	ErrorAttributes map[string]interface{}
+
+	// Panic is the value of the panic recovered while handling the
+	// request, if any.
+	Panic interface{}
+
+	// Latency is the time spent handling the request. It is only available
+	// when the request is handled by EchoMiddleware or WrapFasthttp.
+	Latency time.Duration
+}
+
+func (r *Response) intercept(statusCode, size int) {
+	if len(r.onResponse) == 0 {
+		return
+	}
+
+	event := ResponseEvent{
+		Method:          r.requestMethod(),
+		Path:            r.requestPath(),
+		StatusCode:      statusCode,
+		Size:            size,
+		ErrorKind:       r.errorKind,
+		ErrorAttributes: r.errorAttrs,
+		Panic:           r.panicValue,
+	}
+	if !r.startedAt.IsZero() {
+		event.Latency = time.Since(r.startedAt)
+	}
+
+	for _, interceptor := range r.onResponse {
+		interceptor(event)
+	}
+}
+
+// statusRecorder records the status code and the body size of responses
+// written by net/http helpers, like http.ServeContent.
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	size       int
+}
+
+func (s *statusRecorder) WriteHeader(statusCode int) {
+	s.statusCode = statusCode
+	s.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (s *statusRecorder) Write(p []byte) (int, error) {
+	n, err := s.ResponseWriter.Write(p)
+	s.size += n
+	return n, err
+}
+
+// bodyCounter counts the bytes of a fasthttp body stream, calling done when
+// fasthttp closes it, after the body is sent.
+type bodyCounter struct {
+	io.Reader
+	size int
+	done func(size int)
+}
+
+func (b *bodyCounter) Read(p []byte) (int, error) {
+	n, err := b.Reader.Read(p)
+	b.size += n
+	return n, err
+}
+
+func (b *bodyCounter) Close() error {
+	b.done(b.size)
+
+	if c, ok := b.Reader.(io.Closer); ok {
+		return c.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package response
+
+import (
+	"errors"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/valyala/fasthttp"
+)
+
+// FasthttpHandler is a fasthttp request handler that receives the request
+// Response and may return an error to be forwarded.
+type FasthttpHandler func(ctx *fasthttp.RequestCtx, r *Response) error
+
+// EchoMiddleware creates an echo middleware that attaches a Response to the
+// request context, which handlers can retrieve with RetrieveFromContext,
+// forwards errors returned by handlers with ForwardError and recovers
+// panics into an internal error response. Errors created by echo itself
+// (echo.HTTPError) are left for the echo error handler.
+func EchoMiddleware(options *Options) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			r := NewFromEcho(c, options)
+			r.startedAt = time.Now()
+			req := c.Request()
+			c.SetRequest(req.WithContext(AppendResponseToContext(req.Context(), r)))
+
+			defer func() {
+				if rec := recover(); rec != nil {
+					err = r.forwardPanic(rec)
+				}
+			}()
+
+			if err := next(c); err != nil {
+				var httpErr *echo.HTTPError
+				if errors.As(err, &httpErr) || c.Response().Committed {
+					return err
+				}
+
+				return r.ForwardError(err)
+			}
+
+			return nil
+		}
+	}
+}
+
+// WrapFasthttp converts handler into a fasthttp.RequestHandler that creates
+// the request Response, also attaching it to the request context (ctx
+// user values), forwards returned errors with ForwardError and recovers
+// panics into an internal error response.
+func WrapFasthttp(handler FasthttpHandler, options *Options) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		r := NewFromFasthttp(ctx, options)
+		r.startedAt = time.Now()
+		ctx.SetUserValue(responseContextKey{}, r)
+
+		defer func() {
+			if rec := recover(); rec != nil {
+				_ = r.forwardPanic(rec)
+			}
+		}()
+
+		if err := handler(ctx, r); err != nil {
+			_ = r.ForwardError(err)
+		}
+	}
+}
+
+// forwardPanic sends an internal error response for a recovered panic,
+// without exposing its details to the client. The panic value and its
+// stack are given to Options.OnPanic and the panic value to interceptors.
+// http.ErrAbortHandler is used to abort the response on purpose, so it is
+// panicked again for net/http to handle. fasthttp does not recover panics,
+// so its connection is closed instead.
+func (r *Response) forwardPanic(rec interface{}) error {
+	if rec == http.ErrAbortHandler {
+		if fctx, ok := r.ctx.(*fasthttp.RequestCtx); ok {
+			fctx.SetConnectionClose()
+			return nil
+		}
+
+		panic(rec)
+	}
+
+	if r.onPanic != nil {
+		r.onPanic(rec, debug.Stack())
+	}
+
+	r.panicValue = rec
+	return r.forwardError(http.StatusInternalServerError,
+		newResponseError(&responseErrorOptions{
+			Source:  r.serviceName,
+			Message: internalServerErrorMsg,
+		}),
+	)
+}
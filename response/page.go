@@ -0,0 +1,121 @@
+package response
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PageInfo describes the page of results sent by ForwardPage. Pages can be
+// either number based (Page and PageSize) or cursor based (NextCursor).
+type PageInfo struct {
+	// Total is the number of items available, considering all pages.
+	Total int64
+
+	// Page is the current page number, starting at 1.
+	Page int
+
+	// PageSize is the maximum number of items of a page.
+	PageSize int
+
+	// NextCursor points to the next page when cursor based pagination is
+	// used. It must be empty at the last page.
+	NextCursor string
+
+	// LinkBaseURL, when set, enables RFC 5988 Link headers pointing to the
+	// other pages, built from this URL.
+	LinkBaseURL string
+}
+
+type pageEnvelope struct {
+	Items      interface{} `json:"items"`
+	Total      int64       `json:"total"`
+	Page       int         `json:"page,omitempty"`
+	PageSize   int         `json:"page_size,omitempty"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// ForwardPage sends a page of results wrapped inside a consistent envelope,
+// holding the items, the total of items and how to reach the next page.
+func (r *Response) ForwardPage(data interface{}, page PageInfo) error {
+	if data == nil {
+		data = []interface{}{}
+	}
+
+	if page.LinkBaseURL != "" {
+		links, err := pageLinks(page)
+		if err != nil {
+			return err
+		}
+		if links != "" {
+			r.AddHeader("Link", links)
+		}
+	}
+
+	return r.forwardOutput(http.StatusOK, &pageEnvelope{
+		Items:      data,
+		Total:      page.Total,
+		Page:       page.Page,
+		PageSize:   page.PageSize,
+		NextCursor: page.NextCursor,
+	})
+}
+
+// pageLinks builds the Link header value with the relations available for
+// the page.
+func pageLinks(page PageInfo) (string, error) {
+	base, err := url.Parse(page.LinkBaseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid page link base URL '%s': %w", page.LinkBaseURL, err)
+	}
+
+	var links []string
+	link := func(rel string, params map[string]string) {
+		u := *base
+		q := u.Query()
+		for k, v := range params {
+			q.Set(k, v)
+		}
+		u.RawQuery = q.Encode()
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, u.String(), rel))
+	}
+
+	if page.NextCursor != "" {
+		params := map[string]string{"cursor": page.NextCursor}
+		if page.PageSize > 0 {
+			params["page_size"] = strconv.Itoa(page.PageSize)
+		}
+
+		link("next", params)
+		return strings.Join(links, ", "), nil
+	}
+
+	if page.Page <= 0 || page.PageSize <= 0 {
+		return "", nil
+	}
+
+	last := int((page.Total + int64(page.PageSize) - 1) / int64(page.PageSize))
+	if last < 1 {
+		last = 1
+	}
+
+	pageParams := func(n int) map[string]string {
+		return map[string]string{
+			"page":      strconv.Itoa(n),
+			"page_size": strconv.Itoa(page.PageSize),
+		}
+	}
+
+	link("first", pageParams(1))
+	if page.Page > 1 {
+		link("prev", pageParams(page.Page-1))
+	}
+	if page.Page < last {
+		link("next", pageParams(page.Page+1))
+	}
+	link("last", pageParams(last))
+
+	return strings.Join(links, ", "), nil
+}
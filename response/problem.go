@@ -0,0 +1,98 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/labstack/echo/v4"
+	"github.com/valyala/fasthttp"
+)
+
+// ErrorFormat is the schema used by the response to send errors.
+type ErrorFormat int
+
+const (
+	// ErrorFormatDefault sends errors using the internal error schema.
+	ErrorFormatDefault ErrorFormat = iota
+
+	// ErrorFormatProblem sends errors as RFC 7807 problem details, using
+	// the application/problem+json content type.
+	ErrorFormatProblem
+)
+
+const MediaTypeProblemJSON = "application/problem+json"
+
+// problemDetails is the RFC 7807 representation of an error. Fields that
+// are not part of the RFC are sent as extension members.
+type problemDetails struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	Code        int               `json:"code,omitempty"`
+	Source      string            `json:"source,omitempty"`
+	Details     string            `json:"details,omitempty"`
+	Destination string            `json:"destination,omitempty"`
+	Fields      []*Field          `json:"fields,omitempty"`
+	Retryable   bool              `json:"retryable,omitempty"`
+	RetryAfter  int64             `json:"retry_after,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// forwardError sends an error using the configured error format.
+func (r *Response) forwardError(statusCode int, e *responseError) error {
+	if e.RetryAfter > 0 {
+		r.SetHeader("Retry-After", strconv.FormatInt(e.RetryAfter, 10))
+	}
+
+	if r.errorFormat != ErrorFormatProblem {
+		return r.forwardOutput(statusCode, e)
+	}
+
+	statusCode = r.responseCode(statusCode)
+	out, err := json.Marshal(&problemDetails{
+		Type:        "about:blank",
+		Title:       http.StatusText(statusCode),
+		Status:      statusCode,
+		Detail:      e.Message,
+		Instance:    r.requestPath(),
+		Code:        e.Code,
+		Source:      e.Source,
+		Details:     e.Details,
+		Destination: e.Destination,
+		Fields:      e.Fields,
+		Retryable:   e.Retryable,
+		RetryAfter:  e.RetryAfter,
+		Metadata:    e.Metadata,
+	})
+	if err != nil {
+		return err
+	}
+
+	out, err = r.canonicalize(out)
+	if err != nil {
+		return err
+	}
+
+	return r.writeOutput(statusCode, MediaTypeProblemJSON, out)
+}
+
+func (r *Response) requestPath() string {
+	if fctx, ok := r.ctx.(*fasthttp.RequestCtx); ok {
+		return string(fctx.Path())
+	}
+
+	if ectx, ok := r.ctx.(echo.Context); ok {
+		return ectx.Request().URL.Path
+	}
+
+	if gctx, ok := r.ctx.(*gin.Context); ok {
+		return gctx.Request.URL.Path
+	}
+
+	return ""
+}
@@ -0,0 +1,99 @@
+package response
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/labstack/echo/v4"
+	"github.com/valyala/fasthttp"
+
+	perrors "github.com/rsfreitas/go-pocket-utils/errors"
+)
+
+const rateLimitExceededMsg = "rate limit exceeded"
+
+// RateLimitInfo holds the rate limit state of a client (tenant).
+type RateLimitInfo struct {
+	// Limit is the maximum number of requests allowed in the current window.
+	Limit int
+
+	// Remaining is the number of requests still available in the current
+	// window.
+	Remaining int
+
+	// Reset is the time remaining until the current window ends.
+	Reset time.Duration
+}
+
+// RateLimiter is a behavior that a rate limiter must implement to be used
+// by a Response. Identifying the tenant from the request context is up to
+// the implementation.
+type RateLimiter interface {
+	// Allow checks if the current request can be handled, giving the rate
+	// limit state of its client.
+	Allow(ctx context.Context) (*RateLimitInfo, bool)
+}
+
+// EnforceRateLimit checks with the configured RateLimiter if the current
+// request can be handled. Every response sent afterward carries the
+// RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers. If the
+// limit was exceeded, a 429 error response is sent and true is returned,
+// so the handler must stop.
+func (r *Response) EnforceRateLimit() (bool, error) {
+	if r.rateLimiter == nil {
+		return false, nil
+	}
+
+	info, ok := r.rateLimiter.Allow(r.requestContext())
+	r.rateLimit = info
+	if ok {
+		return false, nil
+	}
+
+	return true, r.ForwardError(&perrors.Error{
+		Code:        perrors.CodeResourceExhausted,
+		ServiceName: r.serviceName,
+		Message:     rateLimitExceededMsg,
+		Kind:        perrors.KindResourceExhausted,
+	})
+}
+
+func (r *Response) requestContext() context.Context {
+	if fctx, ok := r.ctx.(*fasthttp.RequestCtx); ok {
+		return fctx
+	}
+
+	if ectx, ok := r.ctx.(echo.Context); ok {
+		return ectx.Request().Context()
+	}
+
+	if gctx, ok := r.ctx.(*gin.Context); ok {
+		return gctx.Request.Context()
+	}
+
+	return context.Background()
+}
+
+// rateLimitHeaders gives the rate limit headers that must be added into the
+// response, if any.
+func (r *Response) rateLimitHeaders(statusCode int) map[string]string {
+	if r.rateLimit == nil {
+		return nil
+	}
+
+	reset := strconv.Itoa(int(math.Ceil(r.rateLimit.Reset.Seconds())))
+	headers := map[string]string{
+		"RateLimit-Limit":     strconv.Itoa(r.rateLimit.Limit),
+		"RateLimit-Remaining": strconv.Itoa(max(r.rateLimit.Remaining, 0)),
+		"RateLimit-Reset":     reset,
+	}
+
+	if statusCode == fasthttp.StatusTooManyRequests {
+		headers["Retry-After"] = reset
+	}
+
+	return headers
+}
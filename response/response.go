@@ -2,13 +2,19 @@ package response
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/gin-gonic/gin"
+	"github.com/gofiber/fiber/v2"
 	"github.com/labstack/echo/v4"
 	"github.com/valyala/fasthttp"
 	"google.golang.org/grpc/status"
+
+	perrors "github.com/rsfreitas/go-pocket-utils/errors"
 )
 
 const (
@@ -33,10 +39,63 @@ type Response struct {
 	serviceName string
 	contentType string
 	ctx         interface{}
+	rateLimiter RateLimiter
+	rateLimit   *RateLimitInfo
+	canonical   bool
+	headers     http.Header
+	errorFormat ErrorFormat
+	errorStatus map[string]int
+	compression *CompressionOptions
+	etag        bool
+	errorKind   string
+	errorAttrs  map[string]interface{}
+	startedAt   time.Time
+	onResponse  []func(ResponseEvent)
+	envelope    *EnvelopeOptions
+	onPanic     func(value interface{}, stack []byte)
+	panicValue  interface{}
 }
 
 type Options struct {
 	ServiceName string
+
+	// RateLimiter, when set, allows the response to enforce rate limits
+	// with EnforceRateLimit.
+	RateLimiter RateLimiter
+
+	// CanonicalJSON renders JSON bodies with all map keys sorted and without
+	// insignificant whitespaces, so responses are byte-stable for caching,
+	// signing and snapshot tests.
+	CanonicalJSON bool
+
+	// ErrorFormat selects the schema used to send errors. By default, the
+	// internal error schema is used.
+	ErrorFormat ErrorFormat
+
+	// ErrorStatusCodes overrides, for this response only, the HTTP status
+	// code used for service errors of each kind.
+	ErrorStatusCodes map[string]int
+
+	// Compression, when set, compresses response bodies accepted by the
+	// client as gzip or brotli.
+	Compression *CompressionOptions
+
+	// ETag computes ETag headers for successful responses, answering
+	// requests with a matching If-None-Match with 304 Not Modified.
+	ETag bool
+
+	// Interceptors are called after every response is sent, allowing
+	// access logs and metrics to be emitted.
+	Interceptors []func(ResponseEvent)
+
+	// Envelope, when set, wraps all ForwardSuccess payloads inside a
+	// standard envelope. Payloads already encoded by the handler are sent
+	// as they are.
+	Envelope *EnvelopeOptions
+
+	// OnPanic is called with the value and the stack of panics recovered by
+	// EchoMiddleware and WrapFasthttp, allowing them to be logged.
+	OnPanic func(value interface{}, stack []byte)
 }
 
 // NewFromFasthttp creates a new response container for HTTP handlers return data using a
@@ -46,6 +105,15 @@ func NewFromFasthttp(ctx *fasthttp.RequestCtx, options *Options) *Response {
 		serviceName: options.ServiceName,
 		contentType: string(ctx.Request.Header.Peek(contentTypeHeader)),
 		ctx:         ctx,
+		rateLimiter: options.RateLimiter,
+		canonical:   options.CanonicalJSON,
+		errorFormat: options.ErrorFormat,
+		errorStatus: options.ErrorStatusCodes,
+		compression: options.Compression,
+		etag:        options.ETag,
+		onResponse:  options.Interceptors,
+		envelope:    options.Envelope,
+		onPanic:     options.OnPanic,
 	}
 }
 
@@ -54,43 +122,112 @@ func NewFromEcho(ctx echo.Context, options *Options) *Response {
 		serviceName: options.ServiceName,
 		contentType: "application/json",
 		ctx:         ctx,
+		rateLimiter: options.RateLimiter,
+		canonical:   options.CanonicalJSON,
+		errorFormat: options.ErrorFormat,
+		errorStatus: options.ErrorStatusCodes,
+		compression: options.Compression,
+		etag:        options.ETag,
+		onResponse:  options.Interceptors,
+		envelope:    options.Envelope,
+		onPanic:     options.OnPanic,
+	}
+}
+
+// NewFromFiber creates a new response container for fiber handlers. Since
+// fiber is built on top of fasthttp, it shares all fasthttp behavior,
+// including setting custom headers through user values (c.Locals).
+func NewFromFiber(ctx *fiber.Ctx, options *Options) *Response {
+	return NewFromFasthttp(ctx.Context(), options)
+}
+
+// NewFromGin creates a new response container for gin handlers. The
+// response uses the same content type of the request, or JSON if none was
+// sent.
+func NewFromGin(ctx *gin.Context, options *Options) *Response {
+	contentType := ctx.ContentType()
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	return &Response{
+		serviceName: options.ServiceName,
+		contentType: contentType,
+		ctx:         ctx,
+		rateLimiter: options.RateLimiter,
+		canonical:   options.CanonicalJSON,
+		errorFormat: options.ErrorFormat,
+		errorStatus: options.ErrorStatusCodes,
+		compression: options.Compression,
+		etag:        options.ETag,
+		onResponse:  options.Interceptors,
+		envelope:    options.Envelope,
+		onPanic:     options.OnPanic,
 	}
 }
 
 func (r *Response) ForwardAuthenticationError(err error) error {
+	if ferror, ok := r.knownServiceError(err); ok {
+		r.errorKind = ferror.Kind
+		r.errorAttrs = ferror.Attributes
+		return r.forwardError(ferror.ResponseCode(r.errorStatus), ferror.ToResponseError())
+	}
+
 	ferror, err := serviceErrorFromString(err.Error())
 	if err != nil {
-		return r.forwardOutput(fasthttp.StatusInternalServerError,
+		return r.forwardError(fasthttp.StatusInternalServerError,
 			newResponseError(&responseErrorOptions{
 				Message: internalServerErrorMsg,
 				Details: err.Error(),
 			}),
 		)
 	}
-	if ferror.IsKnownError() {
-		return r.forwardOutput(ferror.ResponseCode(), ferror.ToResponseError())
+	if ferror.IsKnownError(r.errorStatus) {
+		r.errorKind = ferror.Kind
+		r.errorAttrs = ferror.Attributes
+		return r.forwardError(ferror.ResponseCode(r.errorStatus), ferror.ToResponseError())
 	}
 
 	return nil
 }
 
 func (r *Response) ForwardError(err error) error {
+	if ferror, ok := r.knownServiceError(err); ok {
+		r.errorKind = ferror.Kind
+		r.errorAttrs = ferror.Attributes
+		return r.forwardError(ferror.ResponseCode(r.errorStatus), ferror.ToResponseError())
+	}
+
+	var bindErr *BindError
+	if errors.As(err, &bindErr) {
+		r.errorKind = string(perrors.KindValidation)
+		return r.forwardError(fasthttp.StatusBadRequest,
+			newResponseError(&responseErrorOptions{
+				Source:  r.serviceName,
+				Message: bindErrorMsg,
+				Fields:  bindErr.Fields,
+			}),
+		)
+	}
+
 	ferror, err := serviceErrorFromString(err.Error())
 	if err != nil {
-		return r.forwardOutput(fasthttp.StatusInternalServerError,
+		return r.forwardError(fasthttp.StatusInternalServerError,
 			newResponseError(&responseErrorOptions{
 				Message: internalServerErrorMsg,
 				Details: err.Error(),
 			}),
 		)
 	}
-	if ferror.IsKnownError() {
-		return r.forwardOutput(ferror.ResponseCode(), ferror.ToResponseError())
+	if ferror.IsKnownError(r.errorStatus) {
+		r.errorKind = ferror.Kind
+		r.errorAttrs = ferror.Attributes
+		return r.forwardError(ferror.ResponseCode(r.errorStatus), ferror.ToResponseError())
 	}
 
 	// A gRPC service can send "gRPC" errors in case of unexpected errors
 	if sts, ok := status.FromError(err); ok {
-		return r.forwardOutput(fasthttp.StatusInternalServerError,
+		return r.forwardError(fasthttp.StatusInternalServerError,
 			newResponseError(&responseErrorOptions{
 				Message: internalServerErrorMsg,
 				Details: sts.Message(),
@@ -100,12 +237,12 @@ func (r *Response) ForwardError(err error) error {
 
 	// In case some parsing failed.
 	if res, ok := jsonError(err); ok {
-		return r.forwardOutput(fasthttp.StatusBadRequest, res)
+		return r.forwardError(fasthttp.StatusBadRequest, res)
 	}
 
 	// Forward the original error if none of the above error checks were
 	// successful.
-	return r.forwardOutput(fasthttp.StatusInternalServerError,
+	return r.forwardError(fasthttp.StatusInternalServerError,
 		newResponseError(&responseErrorOptions{
 			Source:  r.serviceName,
 			Message: internalServerErrorMsg,
@@ -121,7 +258,7 @@ func (r *Response) ForwardSuccess(data interface{}) error {
 			data = h.HttpResponse()
 		}
 
-		return r.forwardOutput(fasthttp.StatusOK, data)
+		return r.forwardOutput(fasthttp.StatusOK, r.wrapSuccess(data))
 	}
 
 	if _, ok := r.ctx.(echo.Context); ok {
@@ -131,56 +268,205 @@ func (r *Response) ForwardSuccess(data interface{}) error {
 				return err
 			}
 
-			return r.forwardOutput(fasthttp.StatusOK, string(b))
+			return r.forwardOutput(fasthttp.StatusOK, r.wrapSuccessBytes(b))
 		}
+
+		return r.forwardOutput(fasthttp.StatusOK, r.wrapSuccess(data))
+	}
+
+	if _, ok := r.ctx.(*gin.Context); ok {
+		if h, ok := data.(ResponserEcho); ok {
+			b, err := h.HttpResponseBytes()
+			if err != nil {
+				return err
+			}
+
+			return r.forwardOutput(fasthttp.StatusOK, r.wrapSuccessBytes(b))
+		}
+		if h, ok := data.(ResponserFasthttp); ok {
+			data = h.HttpResponse()
+		}
+
+		return r.forwardOutput(fasthttp.StatusOK, r.wrapSuccess(data))
 	}
 
 	return nil
 }
 
+// ForwardNoContent sends a 204 No Content response.
+func (r *Response) ForwardNoContent() error {
+	return r.writeEmpty(http.StatusNoContent)
+}
+
+// ForwardCreated sends a 201 Created response with data, pointing to the
+// created resource with the Location header.
+func (r *Response) ForwardCreated(location string, data interface{}) error {
+	if location != "" {
+		r.SetHeader("Location", location)
+	}
+
+	return r.forwardOutput(http.StatusCreated, r.wrapSuccess(data))
+}
+
+// ForwardRedirect redirects the client to url using status, which must be
+// a redirection (3xx) status code.
+func (r *Response) ForwardRedirect(status int, url string) error {
+	if status < http.StatusMultipleChoices || status > http.StatusPermanentRedirect {
+		return fmt.Errorf("invalid redirect status code '%d'", status)
+	}
+
+	r.SetHeader("Location", url)
+	return r.writeEmpty(status)
+}
+
 func (r *Response) forwardOutput(statusCode int, data interface{}) error {
-	if fctx, ok := r.ctx.(*fasthttp.RequestCtx); ok {
-		out, err := json.Marshal(data)
-		if err != nil {
-			return r.ForwardError(err)
-		}
+	out, contentType, err := r.encode(data)
+	if err != nil {
+		return r.ForwardError(err)
+	}
 
-		r.setFasthttpCustomHeaders(fctx)
+	return r.writeOutput(statusCode, contentType, out)
+}
 
-		if v := fctx.UserValue(customResponseCode); v != nil {
-			if c, ok := v.(int); ok {
-				statusCode = c
-			}
-		}
+// writeOutput sends an already encoded body.
+func (r *Response) writeOutput(statusCode int, contentType string, out []byte) error {
+	statusCode = r.responseCode(statusCode)
+	if r.notModified(statusCode, out) {
+		return r.writeEmpty(http.StatusNotModified)
+	}
+
+	if compressed, encoding := r.compress(contentType, out); encoding != "" {
+		out = compressed
+		r.SetHeader("Content-Encoding", encoding)
+		r.AddHeader("Vary", "Accept-Encoding")
+	}
 
+	r.writeHeaders(statusCode)
+	defer r.intercept(statusCode, len(out))
+
+	if fctx, ok := r.ctx.(*fasthttp.RequestCtx); ok {
 		fctx.Response.SetStatusCode(statusCode)
-		fctx.Response.Header.SetContentType(r.contentType)
+		fctx.Response.Header.SetContentType(contentType)
 		fctx.Response.SetBodyRaw(out)
 
 		return nil
 	}
 
 	if ectx, ok := r.ctx.(echo.Context); ok {
-		if r.customCode != 0 {
-			statusCode = r.customCode
+		return ectx.Blob(statusCode, contentType, out)
+	}
+
+	if gctx, ok := r.ctx.(*gin.Context); ok {
+		gctx.Data(statusCode, contentType, out)
+	}
+
+	return nil
+}
+
+// writeEmpty sends a response without a body, keeping its headers.
+func (r *Response) writeEmpty(statusCode int) error {
+	r.writeHeaders(statusCode)
+	defer r.intercept(statusCode, 0)
+
+	if fctx, ok := r.ctx.(*fasthttp.RequestCtx); ok {
+		fctx.Response.SetStatusCode(statusCode)
+		fctx.Response.SkipBody = true
+
+		return nil
+	}
+
+	if ectx, ok := r.ctx.(echo.Context); ok {
+		return ectx.NoContent(statusCode)
+	}
+
+	if gctx, ok := r.ctx.(*gin.Context); ok {
+		gctx.Status(statusCode)
+		gctx.Writer.WriteHeaderNow()
+	}
+
+	return nil
+}
+
+// responseCode gives the status code that must be used by the response,
+// considering custom codes set by the handler.
+func (r *Response) responseCode(statusCode int) int {
+	if r.customCode != 0 {
+		statusCode = r.customCode
+	}
+
+	if fctx, ok := r.ctx.(*fasthttp.RequestCtx); ok {
+		if c, ok := fctx.UserValue(customResponseCode).(int); ok {
+			statusCode = c
 		}
+	}
 
-		ectx.Response().Header().Set("Content-Type", r.contentType)
-		out, ok := data.(string)
-		if !ok {
-			b, err := json.Marshal(data)
-			if err != nil {
-				return r.ForwardError(err)
+	if gctx, ok := r.ctx.(*gin.Context); ok {
+		if v, ok := gctx.Get(customResponseCode); ok {
+			if c, ok := v.(int); ok {
+				statusCode = c
 			}
-			out = string(b)
 		}
+	}
+
+	return statusCode
+}
+
+// SetHeader sets a header to be sent with the response, replacing any
+// value already set for the same key.
+func (r *Response) SetHeader(key, value string) {
+	if r.headers == nil {
+		r.headers = make(http.Header)
+	}
+
+	r.headers.Set(key, value)
+}
+
+// AddHeader adds a value to a header to be sent with the response.
+func (r *Response) AddHeader(key, value string) {
+	if r.headers == nil {
+		r.headers = make(http.Header)
+	}
+
+	r.headers.Add(key, value)
+}
 
-		if err := ectx.String(statusCode, out); err != nil {
-			return err
+// writeHeaders adds all headers of the response into the backend response.
+func (r *Response) writeHeaders(statusCode int) {
+	headers := r.headers.Clone()
+	if headers == nil {
+		headers = make(http.Header)
+	}
+	for k, v := range r.rateLimitHeaders(statusCode) {
+		headers.Set(k, v)
+	}
+
+	if fctx, ok := r.ctx.(*fasthttp.RequestCtx); ok {
+		r.setFasthttpCustomHeaders(fctx)
+		for k, values := range headers {
+			for _, v := range values {
+				fctx.Response.Header.Add(k, v)
+			}
 		}
+
+		return
 	}
 
-	return nil
+	var h http.Header
+	if ectx, ok := r.ctx.(echo.Context); ok {
+		h = ectx.Response().Header()
+	}
+	if gctx, ok := r.ctx.(*gin.Context); ok {
+		h = gctx.Writer.Header()
+	}
+	if h == nil {
+		return
+	}
+
+	for k, values := range headers {
+		for _, v := range values {
+			h.Add(k, v)
+		}
+	}
 }
 
 func (r *Response) setFasthttpCustomHeaders(ctx *fasthttp.RequestCtx) {
@@ -196,21 +482,72 @@ func (r *Response) SetContentType(contentType string) {
 	r.contentType = contentType
 }
 
-func SetResponseCode(ctx context.Context, code int) {
+// ErrNoResponse is returned when there is no Response attached to the
+// context.
+var ErrNoResponse = errors.New("no response attached to the context")
+
+// responseContextKey is the key used to attach a Response to a context.
+type responseContextKey struct{}
+
+// SetResponseCode sets a custom status code for the response of the
+// handler. It returns ErrNoResponse, without changing anything, when ctx
+// does not hold a response.
+func SetResponseCode(ctx context.Context, code int) error {
 	if c, ok := ctx.(*fasthttp.RequestCtx); ok {
 		c.SetUserValue(customResponseCode, code)
-		return
+		return nil
+	}
+
+	if c, ok := ctx.(*gin.Context); ok {
+		c.Set(customResponseCode, code)
+		return nil
+	}
+
+	r, ok := FromContext(ctx)
+	if !ok {
+		return ErrNoResponse
 	}
 
-	r := RetrieveFromContext(ctx)
 	r.customCode = code
+	return nil
+}
+
+// SetHeader sets a header to be sent with the response attached to ctx.
+// Requests handled by fasthttp (or fiber) and gin without an attached
+// response have the header set directly. It returns ErrNoResponse when
+// there is no response to set the header.
+func SetHeader(ctx context.Context, key, value string) error {
+	if r, ok := FromContext(ctx); ok {
+		r.SetHeader(key, value)
+		return nil
+	}
+
+	if c, ok := ctx.(*fasthttp.RequestCtx); ok {
+		c.SetUserValue(customHeaderPrefix+key, value)
+		return nil
+	}
+
+	if c, ok := ctx.(*gin.Context); ok {
+		c.Header(key, value)
+		return nil
+	}
+
+	return ErrNoResponse
 }
 
 func AppendResponseToContext(ctx context.Context, r *Response) context.Context {
-	return context.WithValue(ctx, "response", r)
+	return context.WithValue(ctx, responseContextKey{}, r)
 }
 
+// RetrieveFromContext gives the Response attached to ctx, or nil if there
+// is none.
 func RetrieveFromContext(ctx context.Context) *Response {
-	r := ctx.Value("response")
-	return r.(*Response)
+	r, _ := FromContext(ctx)
+	return r
+}
+
+// FromContext gives the Response attached to ctx and whether it was found.
+func FromContext(ctx context.Context) (*Response, bool) {
+	r, ok := ctx.Value(responseContextKey{}).(*Response)
+	return r, ok && r != nil
 }
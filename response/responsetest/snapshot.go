@@ -0,0 +1,174 @@
+// Package responsetest provides helpers to test HTTP responses produced by
+// the response package.
+package responsetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	defaultSnapshotDir = "testdata/snapshots"
+	updateSnapshotsEnv = "UPDATE_SNAPSHOTS"
+)
+
+// volatileHeaders are headers not included in snapshots since their values
+// change between executions.
+var volatileHeaders = map[string]bool{
+	"Date": true,
+}
+
+// Snapshotter captures responses into snapshot files and compares them on
+// subsequent runs, making breaking changes in response contracts visible.
+type Snapshotter struct {
+	dir     string
+	version int
+	update  bool
+}
+
+type SnapshotOptions struct {
+	// Dir is the directory where snapshot files are stored. Default is
+	// "testdata/snapshots".
+	Dir string
+
+	// Version is the contract version. Every version has its own snapshot
+	// files, so intentional breaking changes can be made by bumping it.
+	Version int
+
+	// Update forces existing snapshots to be rewritten. It can also be
+	// enabled by setting the UPDATE_SNAPSHOTS environment variable.
+	Update bool
+}
+
+// Snapshot is the captured content of a response.
+type Snapshot struct {
+	StatusCode int               `json:"status_code"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       interface{}       `json:"body,omitempty"`
+}
+
+// NewSnapshotter creates a new Snapshotter.
+func NewSnapshotter(options SnapshotOptions) *Snapshotter {
+	dir := options.Dir
+	if dir == "" {
+		dir = defaultSnapshotDir
+	}
+
+	return &Snapshotter{
+		dir:     dir,
+		version: options.Version,
+		update:  options.Update || os.Getenv(updateSnapshotsEnv) != "",
+	}
+}
+
+// MatchFasthttp compares the response written into a fasthttp request
+// context with the snapshot called name.
+func (s *Snapshotter) MatchFasthttp(t testing.TB, name string, ctx *fasthttp.RequestCtx) {
+	t.Helper()
+
+	headers := make(map[string]string)
+	ctx.Response.Header.VisitAll(func(key, value []byte) {
+		headers[string(key)] = string(value)
+	})
+
+	s.match(t, name, ctx.Response.StatusCode(), headers, ctx.Response.Body())
+}
+
+// MatchRecorder compares the response written into a recorder, like the ones
+// used to test echo handlers, with the snapshot called name.
+func (s *Snapshotter) MatchRecorder(t testing.TB, name string, rec *httptest.ResponseRecorder) {
+	t.Helper()
+
+	headers := make(map[string]string)
+	for key := range rec.Header() {
+		headers[http.CanonicalHeaderKey(key)] = rec.Header().Get(key)
+	}
+
+	s.match(t, name, rec.Code, headers, rec.Body.Bytes())
+}
+
+func (s *Snapshotter) match(t testing.TB, name string, statusCode int, headers map[string]string, body []byte) {
+	t.Helper()
+
+	for key := range volatileHeaders {
+		delete(headers, key)
+	}
+
+	current, err := encodeSnapshot(&Snapshot{
+		StatusCode: statusCode,
+		Headers:    headers,
+		Body:       decodeBody(body),
+	})
+	if err != nil {
+		t.Fatalf("could not encode snapshot '%s': %v", name, err)
+	}
+
+	filename := filepath.Join(s.dir, fmt.Sprintf("v%d", s.version), name+".json")
+	previous, err := os.ReadFile(filename)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("could not read snapshot '%s': %v", name, err)
+	}
+
+	if previous == nil || s.update {
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatalf("could not create snapshot directory: %v", err)
+		}
+		if err := os.WriteFile(filename, current, 0644); err != nil {
+			t.Fatalf("could not write snapshot '%s': %v", name, err)
+		}
+
+		return
+	}
+
+	if !bytes.Equal(previous, current) {
+		diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(string(previous)),
+			B:        difflib.SplitLines(string(current)),
+			FromFile: filename,
+			ToFile:   "current",
+			Context:  3,
+		})
+
+		t.Errorf("response does not match snapshot '%s':\n%s", name, diff)
+	}
+}
+
+// decodeBody gives JSON bodies as values, so they are stored formatted and
+// with sorted keys, or as plain strings otherwise.
+func decodeBody(body []byte) interface{} {
+	if len(body) == 0 {
+		return nil
+	}
+	if !json.Valid(body) {
+		return string(body)
+	}
+
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return string(body)
+	}
+
+	return v
+}
+
+func encodeSnapshot(snapshot *Snapshot) ([]byte, error) {
+	b, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(b, '\n'), nil
+}
@@ -2,24 +2,54 @@ package response
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"sync"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+
+	perrors "github.com/rsfreitas/go-pocket-utils/errors"
+)
+
+var (
+	knownServiceErrorsMu sync.RWMutex
+	knownServiceErrors   = map[string]int{
+		"ValidationError":        http.StatusBadRequest,
+		"InternalError":          http.StatusInternalServerError,
+		"NotFoundError":          http.StatusNotFound,
+		"ConditionError":         http.StatusPreconditionFailed,
+		"PermissionError":        http.StatusUnauthorized,
+		"ResourceExhaustedError": http.StatusTooManyRequests,
+		"UnauthenticatedError":   http.StatusUnauthorized,
+		"ConflictError":          http.StatusConflict,
+		"UnavailableError":       http.StatusServiceUnavailable,
+		"TimeoutError":           http.StatusGatewayTimeout,
+		"NotImplementedError":    http.StatusNotImplemented,
+	}
 )
 
-var knownServiceErrors = map[string]bool{
-	"ValidationError": true,
-	"InternalError":   true,
-	"NotFoundError":   true,
-	"ConditionError":  true,
-	"PermissionError": true,
+// RegisterErrorKind adds (or replaces) the HTTP status code used when
+// forwarding service errors of a kind.
+func RegisterErrorKind(kind string, status int) {
+	knownServiceErrorsMu.Lock()
+	defer knownServiceErrorsMu.Unlock()
+
+	knownServiceErrors[kind] = status
 }
 
 type serviceError struct {
-	Code          int32       `json:"code"`
-	ServiceName   string      `json:"service_name"`
-	Message       string      `json:"message"`
-	Destination   string      `json:"destination"`
-	Kind          string      `json:"kind"`
-	SublevelError interface{} `json:"details"`
+	Code          int32                  `json:"code"`
+	ServiceName   string                 `json:"service_name"`
+	Message       string                 `json:"message"`
+	Destination   string                 `json:"destination"`
+	Kind          string                 `json:"kind"`
+	SublevelError interface{}            `json:"details"`
+	Retryable     bool                   `json:"retryable"`
+	RetryAfter    int64                  `json:"retry_after"`
+	Metadata      map[string]string      `json:"metadata"`
+	Fields        []*Field               `json:"fields"`
+	Attributes    map[string]interface{} `json:"attributes"`
 }
 
 func serviceErrorFromString(s string) (*serviceError, error) {
@@ -31,21 +61,88 @@ func serviceErrorFromString(s string) (*serviceError, error) {
 	return &e, nil
 }
 
-func (s *serviceError) IsKnownError() bool {
-	_, ok := knownServiceErrors[s.Kind]
+// serviceErrorFromError gives the serviceError of a framework error.
+func serviceErrorFromError(e *perrors.Error) *serviceError {
+	out := e.Public()
+	return &serviceError{
+		Code:          out.Code,
+		ServiceName:   out.ServiceName,
+		Message:       out.Message,
+		Destination:   out.Destination,
+		Kind:          string(out.Kind),
+		SublevelError: out.SublevelError,
+		Retryable:     out.Retryable,
+		RetryAfter:    out.RetryAfter,
+		Metadata:      out.Metadata,
+		Fields:        fieldsFromViolations(out.Fields),
+		Attributes:    out.Attributes,
+	}
+}
+
+func fieldsFromViolations(violations []perrors.FieldViolation) []*Field {
+	if len(violations) == 0 {
+		return nil
+	}
+
+	fields := make([]*Field, len(violations))
+	for i, v := range violations {
+		fields[i] = &Field{
+			Field:    v.Field,
+			Message:  v.Message,
+			Location: v.Location,
+		}
+	}
+
+	return fields
+}
+
+// knownServiceError looks for a known service error inside err, either as
+// a framework error or as a gRPC status created from one.
+func (r *Response) knownServiceError(err error) (*serviceError, bool) {
+	var e *perrors.Error
+	if errors.As(err, &e) {
+		s := serviceErrorFromError(e)
+		return s, s.IsKnownError(r.errorStatus)
+	}
+
+	if sts, ok := status.FromError(err); ok && sts != nil {
+		for _, detail := range sts.Details() {
+			if _, ok := detail.(*errdetails.ErrorInfo); ok {
+				s := serviceErrorFromError(perrors.FromGRPCStatus(sts))
+				return s, s.IsKnownError(r.errorStatus)
+			}
+		}
+
+		if s, err := serviceErrorFromString(sts.Message()); err == nil {
+			return s, s.IsKnownError(r.errorStatus)
+		}
+	}
+
+	return nil, false
+}
+
+// statusCode gives the HTTP status code of the error kind, checking
+// overrides before the registered kinds.
+func (s *serviceError) statusCode(overrides map[string]int) (int, bool) {
+	if code, ok := overrides[s.Kind]; ok {
+		return code, true
+	}
+
+	knownServiceErrorsMu.RLock()
+	defer knownServiceErrorsMu.RUnlock()
+
+	code, ok := knownServiceErrors[s.Kind]
+	return code, ok
+}
+
+func (s *serviceError) IsKnownError(overrides map[string]int) bool {
+	_, ok := s.statusCode(overrides)
 	return ok
 }
 
-func (s *serviceError) ResponseCode() int {
-	switch s.Kind {
-	case "ValidationError":
-		return http.StatusBadRequest
-	case "NotFoundError":
-		return http.StatusNotFound
-	case "ConditionError":
-		return http.StatusPreconditionFailed
-	case "PermissionError":
-		return http.StatusUnauthorized
+func (s *serviceError) ResponseCode(overrides map[string]int) int {
+	if code, ok := s.statusCode(overrides); ok {
+		return code
 	}
 
 	return http.StatusInternalServerError
@@ -57,9 +154,14 @@ func (s *serviceError) ToResponseError() *responseError {
 		Source:      s.ServiceName,
 		Message:     s.Message,
 		Destination: s.Destination,
+		Retryable:   s.Retryable,
+		RetryAfter:  s.RetryAfter,
+		Metadata:    s.Metadata,
+		Fields:      s.Fields,
 	}
 
-	if s.SublevelError != nil {
+	// Structured fields are preferred over parsing the error details.
+	if len(s.Fields) == 0 && s.SublevelError != nil {
 		if s.Kind == "ValidationError" {
 			// Encode the error details into a json string
 			b, _ := json.Marshal(s.SublevelError)
@@ -0,0 +1,187 @@
+package response
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/labstack/echo/v4"
+	"github.com/valyala/fasthttp"
+)
+
+const eventStreamContentType = "text/event-stream"
+
+// Event is a Server-Sent Event sent by ForwardSSE.
+type Event struct {
+	ID    string
+	Event string
+
+	// Data is the event payload. Strings and byte slices are sent as they
+	// are, other values are encoded as JSON.
+	Data interface{}
+
+	// Retry tells the client how long to wait before reconnecting.
+	Retry time.Duration
+}
+
+// flushWriter flushes every write, so the client receives data as soon as
+// it is produced.
+type flushWriter struct {
+	w     io.Writer
+	flush func() error
+	size  int
+}
+
+func (f *flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	f.size += n
+	if err != nil {
+		return n, err
+	}
+
+	return n, f.flush()
+}
+
+// ForwardStream sends a streamed response, where fn writes the body as it
+// is produced. Every write is flushed to the client and fails when the
+// client disconnects.
+//
+// Note that with fasthttp (and fiber), fn is called only after the handler
+// returns, so its error cannot be returned by ForwardStream, it only stops
+// the stream.
+func (r *Response) ForwardStream(contentType string, fn func(w io.Writer) error) error {
+	return r.stream(contentType, nil, func(w io.Writer, _ <-chan struct{}) error {
+		return fn(w)
+	})
+}
+
+// ForwardSSE sends every event received from events to the client as
+// Server-Sent Events, until events is closed or the client disconnects.
+func (r *Response) ForwardSSE(events <-chan Event) error {
+	headers := map[string]string{
+		"Cache-Control": "no-cache",
+		"Connection":    "keep-alive",
+	}
+
+	return r.stream(eventStreamContentType, headers, func(w io.Writer, done <-chan struct{}) error {
+		for {
+			select {
+			case <-done:
+				return nil
+
+			case e, ok := <-events:
+				if !ok {
+					return nil
+				}
+
+				if err := writeEvent(w, e); err != nil {
+					return err
+				}
+			}
+		}
+	})
+}
+
+// stream prepares the response to be streamed and calls fn with a writer
+// that flushes every write. done is closed when the client disconnects, if
+// the backend supports it.
+func (r *Response) stream(contentType string, headers map[string]string, fn func(w io.Writer, done <-chan struct{}) error) error {
+	statusCode := r.responseCode(http.StatusOK)
+
+	for k, v := range headers {
+		r.SetHeader(k, v)
+	}
+	r.writeHeaders(statusCode)
+
+	if fctx, ok := r.ctx.(*fasthttp.RequestCtx); ok {
+		fctx.Response.SetStatusCode(statusCode)
+		fctx.Response.Header.SetContentType(contentType)
+		fctx.SetBodyStreamWriter(func(w *bufio.Writer) {
+			fw := &flushWriter{w: w, flush: w.Flush}
+			_ = fn(fw, nil)
+			r.intercept(statusCode, fw.size)
+		})
+
+		return nil
+	}
+
+	var (
+		w   http.ResponseWriter
+		req *http.Request
+	)
+
+	if ectx, ok := r.ctx.(echo.Context); ok {
+		w, req = ectx.Response(), ectx.Request()
+	}
+	if gctx, ok := r.ctx.(*gin.Context); ok {
+		w, req = gctx.Writer, gctx.Request
+	}
+	if w == nil {
+		return nil
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(statusCode)
+
+	flusher, _ := w.(http.Flusher)
+	fw := &flushWriter{
+		w: w,
+		flush: func() error {
+			if flusher != nil {
+				flusher.Flush()
+			}
+
+			return nil
+		},
+	}
+
+	defer func() {
+		r.intercept(statusCode, fw.size)
+	}()
+
+	return fn(fw, req.Context().Done())
+}
+
+// writeEvent writes an event using the text/event-stream format.
+func writeEvent(w io.Writer, e Event) error {
+	var buf bytes.Buffer
+
+	if e.ID != "" {
+		buf.WriteString(fmt.Sprintf("id: %s\n", e.ID))
+	}
+	if e.Event != "" {
+		buf.WriteString(fmt.Sprintf("event: %s\n", e.Event))
+	}
+	if e.Retry > 0 {
+		buf.WriteString(fmt.Sprintf("retry: %d\n", e.Retry.Milliseconds()))
+	}
+
+	var data string
+	switch v := e.Data.(type) {
+	case nil:
+	case string:
+		data = v
+	case []byte:
+		data = string(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		data = string(b)
+	}
+
+	for _, line := range strings.Split(data, "\n") {
+		buf.WriteString(fmt.Sprintf("data: %s\n", line))
+	}
+	buf.WriteString("\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,145 @@
+package template
+
+import (
+	"sync"
+
+	tparse "text/template/parse"
+)
+
+const accumulatedFunction = "accumulated"
+
+// accumulator is a shared state between all templates of a single Execute
+// call, where templates can register values (e.g. routes of handlers) that
+// other templates (e.g. the router) can later iterate.
+type accumulator struct {
+	mu     sync.Mutex
+	values map[string][]interface{}
+}
+
+func newAccumulator() *accumulator {
+	return &accumulator{
+		values: make(map[string][]interface{}),
+	}
+}
+
+// add appends values into the accumulator called name. It returns an empty
+// string so it can be used inside a template action.
+func (a *accumulator) add(name string, values ...interface{}) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.values[name] = append(a.values[name], values...)
+	return ""
+}
+
+// get gives all values registered into the accumulator called name.
+func (a *accumulator) get(name string) []interface{} {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.values[name]
+}
+
+func (a *accumulator) reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.values = make(map[string][]interface{})
+}
+
+// executionOrder gives the templates in the order they must be executed:
+// templates reading accumulators are executed after all others, so every
+// value is already registered when they run.
+func (t *Templates) executionOrder() []*Info {
+	var producers, consumers []*Info
+
+	for _, template := range t.templates {
+		if !template.binary && t.usesFunction(template, accumulatedFunction) {
+			consumers = append(consumers, template)
+			continue
+		}
+
+		producers = append(producers, template)
+	}
+
+	return append(producers, consumers...)
+}
+
+func (t *Templates) usesFunction(template *Info, name string) bool {
+	var (
+		tree  = tparse.New(template.templateFilename)
+		set   = make(map[string]*tparse.Tree)
+		found bool
+	)
+
+	tree.Mode = tparse.SkipFuncCheck
+	if _, err := tree.Parse(string(template.data), t.delimiters[0], t.delimiters[1], set); err != nil {
+		// Parsing errors are reported when the template is executed.
+		return false
+	}
+
+	visit := func(node tparse.Node) {
+		if n, ok := node.(*tparse.IdentifierNode); ok && n.Ident == name {
+			found = true
+		}
+	}
+
+	for _, tr := range set {
+		walkNodes(tr.Root, visit)
+	}
+	walkNodes(tree.Root, visit)
+
+	return found
+}
+
+// walkNodes calls fn for node and all its children.
+func walkNodes(node tparse.Node, fn func(node tparse.Node)) {
+	fn(node)
+
+	switch n := node.(type) {
+	case *tparse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkNodes(child, fn)
+		}
+
+	case *tparse.ActionNode:
+		walkNodes(n.Pipe, fn)
+
+	case *tparse.IfNode:
+		walkBranchNodes(&n.BranchNode, fn)
+
+	case *tparse.RangeNode:
+		walkBranchNodes(&n.BranchNode, fn)
+
+	case *tparse.WithNode:
+		walkBranchNodes(&n.BranchNode, fn)
+
+	case *tparse.TemplateNode:
+		walkNodes(n.Pipe, fn)
+
+	case *tparse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			walkNodes(cmd, fn)
+		}
+
+	case *tparse.CommandNode:
+		for _, arg := range n.Args {
+			walkNodes(arg, fn)
+		}
+
+	case *tparse.ChainNode:
+		walkNodes(n.Node, fn)
+	}
+}
+
+func walkBranchNodes(n *tparse.BranchNode, fn func(node tparse.Node)) {
+	walkNodes(n.Pipe, fn)
+	walkNodes(n.List, fn)
+	walkNodes(n.ElseList, fn)
+}
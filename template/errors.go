@@ -0,0 +1,104 @@
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// sourceContextLines is the number of lines shown before and after the
+// line where an error happened.
+const sourceContextLines = 2
+
+var (
+	execErrorRegex  = regexp.MustCompile(`(?s)^template: [^:]*:(\d+):(\d+): executing "[^"]*" at <(.*?)>: (.*)$`)
+	parseErrorRegex = regexp.MustCompile(`(?s)^template: [^:]*:(\d+):(?:(\d+):)? (.*)$`)
+)
+
+// ExecutionError is the error returned when a template fails to be parsed
+// or executed, carrying where the problem happened.
+type ExecutionError struct {
+	Template   string
+	Line       int
+	Column     int
+	Expression string
+	Message    string
+
+	// Source holds the template lines around the error.
+	Source string
+	Err    error
+}
+
+func newExecutionError(name string, data []byte, err error) *ExecutionError {
+	e := &ExecutionError{
+		Template: name,
+		Message:  err.Error(),
+		Err:      err,
+	}
+
+	if m := execErrorRegex.FindStringSubmatch(err.Error()); m != nil {
+		e.Line, _ = strconv.Atoi(m[1])
+		e.Column, _ = strconv.Atoi(m[2])
+		e.Expression = m[3]
+		e.Message = m[4]
+	} else if m := parseErrorRegex.FindStringSubmatch(err.Error()); m != nil {
+		e.Line, _ = strconv.Atoi(m[1])
+		e.Column, _ = strconv.Atoi(m[2])
+		e.Message = m[3]
+	}
+
+	if e.Line > 0 {
+		e.Source = sourceContext(string(data), e.Line)
+	}
+
+	return e
+}
+
+func (e *ExecutionError) Error() string {
+	var s strings.Builder
+
+	s.WriteString(fmt.Sprintf("template '%s'", e.Template))
+	if e.Line > 0 {
+		s.WriteString(fmt.Sprintf(", line %d", e.Line))
+	}
+	if e.Column > 0 {
+		s.WriteString(fmt.Sprintf(", column %d", e.Column))
+	}
+	if e.Expression != "" {
+		s.WriteString(fmt.Sprintf(", at <%s>", e.Expression))
+	}
+	s.WriteString(": " + e.Message)
+
+	if e.Source != "" {
+		s.WriteString("\n" + e.Source)
+	}
+
+	return s.String()
+}
+
+func (e *ExecutionError) Unwrap() error {
+	return e.Err
+}
+
+// sourceContext gives the lines around line, with their numbers, marking
+// the line itself.
+func sourceContext(data string, line int) string {
+	var (
+		s     strings.Builder
+		lines = strings.Split(data, "\n")
+		first = max(line-sourceContextLines, 1)
+		last  = min(line+sourceContextLines, len(lines))
+	)
+
+	for i := first; i <= last; i++ {
+		marker := " "
+		if i == line {
+			marker = ">"
+		}
+
+		s.WriteString(fmt.Sprintf("%s %4d | %s\n", marker, i, lines[i-1]))
+	}
+
+	return strings.TrimSuffix(s.String(), "\n")
+}
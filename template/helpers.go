@@ -0,0 +1,185 @@
+package template
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var uuidNamespaces = map[string]uuid.UUID{
+	"dns":  uuid.NameSpaceDNS,
+	"url":  uuid.NameSpaceURL,
+	"oid":  uuid.NameSpaceOID,
+	"x500": uuid.NameSpaceX500,
+}
+
+// uuidV5 derives a deterministic UUID (version 5) from a namespace and a
+// name. The namespace can be one of the well-known ones (dns, url, oid and
+// x500) or any valid UUID.
+func uuidV5(namespace, name string) (string, error) {
+	ns, ok := uuidNamespaces[strings.ToLower(namespace)]
+	if !ok {
+		var err error
+		ns, err = uuid.Parse(namespace)
+		if err != nil {
+			return "", fmt.Errorf("invalid uuid namespace '%s': %w", namespace, err)
+		}
+	}
+
+	return uuid.NewSHA1(ns, []byte(name)).String(), nil
+}
+
+// hashSuffix returns the first length characters of the hex encoded SHA-256
+// of all values, allowing templates to build stable unique names.
+func hashSuffix(length int, values ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(values, "\x00")))
+	s := hex.EncodeToString(sum[:])
+
+	if length > 0 && length < len(s) {
+		return s[:length]
+	}
+
+	return s
+}
+
+// stableName joins a prefix with a short hash of the values, giving a name
+// that remains the same across executions.
+func stableName(prefix string, values ...string) string {
+	return fmt.Sprintf("%s-%s", prefix, hashSuffix(8, values...))
+}
+
+var irregularPlurals = map[string]string{
+	"child":  "children",
+	"person": "people",
+	"man":    "men",
+	"woman":  "women",
+	"mouse":  "mice",
+	"goose":  "geese",
+	"tooth":  "teeth",
+	"foot":   "feet",
+	"index":  "indices",
+	"quiz":   "quizzes",
+
+	// Words ending with -s (or -use) that the -uses rule of singularize
+	// cannot handle.
+	"alias":  "aliases",
+	"bias":   "biases",
+	"canvas": "canvases",
+	"lens":   "lenses",
+	"abuse":  "abuses",
+	"excuse": "excuses",
+	"fuse":   "fuses",
+	"refuse": "refuses",
+	"misuse": "misuses",
+}
+
+var uncountableWords = map[string]bool{
+	"data":        true,
+	"metadata":    true,
+	"information": true,
+	"equipment":   true,
+	"series":      true,
+	"species":     true,
+	"news":        true,
+}
+
+// pluralize gives the plural form of an English word using the most common
+// rules.
+func pluralize(word string) string {
+	lower := strings.ToLower(word)
+	if uncountableWords[lower] {
+		return word
+	}
+	if plural, ok := irregularPlurals[lower]; ok {
+		return matchFirstCase(word, plural)
+	}
+
+	switch {
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !isVowel(lower[len(lower)-2]):
+		return word[:len(word)-1] + "ies"
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"),
+		strings.HasSuffix(lower, "z"), strings.HasSuffix(lower, "ch"),
+		strings.HasSuffix(lower, "sh"):
+		return word + "es"
+	}
+
+	return word + "s"
+}
+
+// singularize gives the singular form of an English word using the most
+// common rules.
+func singularize(word string) string {
+	lower := strings.ToLower(word)
+	if uncountableWords[lower] {
+		return word
+	}
+	for singular, plural := range irregularPlurals {
+		if lower == plural {
+			return matchFirstCase(word, singular)
+		}
+	}
+
+	switch {
+	case strings.HasSuffix(lower, "ies") && len(lower) > 3:
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(lower, "uses") && len(lower) > 4 && !isVowel(lower[len(lower)-5]):
+		// Plural of words ending with -us, like statuses. Words ending with
+		// -use are usually preceded by a vowel, like houses and causes.
+		return word[:len(word)-2]
+	case strings.HasSuffix(lower, "sses"), strings.HasSuffix(lower, "xes"),
+		strings.HasSuffix(lower, "zes"), strings.HasSuffix(lower, "ches"),
+		strings.HasSuffix(lower, "shes"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(lower, "s") && !strings.HasSuffix(lower, "ss"):
+		return word[:len(word)-1]
+	}
+
+	return word
+}
+
+func isVowel(c byte) bool {
+	return strings.IndexByte("aeiou", c) >= 0
+}
+
+// matchFirstCase gives s with its first letter in the same case as the
+// first letter of word.
+func matchFirstCase(word, s string) string {
+	if word != "" && strings.ToUpper(word[:1]) == word[:1] {
+		return strings.ToUpper(s[:1]) + s[1:]
+	}
+
+	return s
+}
+
+// indent adds spaces at the beginning of every line of s.
+func indent(spaces int, s string) string {
+	pad := strings.Repeat(" ", spaces)
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
+
+// nindent is like indent but it also adds a newline before s.
+func nindent(spaces int, s string) string {
+	return "\n" + indent(spaces, s)
+}
+
+func quote(s string) string {
+	return strconv.Quote(s)
+}
+
+// hasPrefix, hasSuffix and join receive the value as their last argument,
+// like their sprig counterparts, so they can be used in pipelines.
+func hasPrefix(prefix, s string) bool {
+	return strings.HasPrefix(s, prefix)
+}
+
+func hasSuffix(suffix, s string) bool {
+	return strings.HasSuffix(s, suffix)
+}
+
+func join(sep string, list []string) string {
+	return strings.Join(list, sep)
+}
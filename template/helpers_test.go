@@ -0,0 +1,80 @@
+package template
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPluralizeRoundTrip(t *testing.T) {
+	tests := []struct {
+		singular string
+		plural   string
+	}{
+		{"user", "users"},
+		{"User", "Users"},
+		{"category", "categories"},
+		{"key", "keys"},
+		{"box", "boxes"},
+		{"match", "matches"},
+		{"wish", "wishes"},
+		{"quiz", "quizzes"},
+		{"address", "addresses"},
+		{"class", "classes"},
+		{"status", "statuses"},
+		{"bus", "buses"},
+		{"campus", "campuses"},
+		{"house", "houses"},
+		{"cause", "causes"},
+		{"use", "uses"},
+		{"excuse", "excuses"},
+		{"alias", "aliases"},
+		{"person", "people"},
+		{"Child", "Children"},
+		{"index", "indices"},
+		{"data", "data"},
+		{"metadata", "metadata"},
+		{"series", "series"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.singular, func(t *testing.T) {
+			if got := pluralize(tt.singular); got != tt.plural {
+				t.Errorf("pluralize(%q) = %q, want %q", tt.singular, got, tt.plural)
+			}
+			if got := singularize(tt.plural); got != tt.singular {
+				t.Errorf("singularize(%q) = %q, want %q", tt.plural, got, tt.singular)
+			}
+		})
+	}
+}
+
+func TestPipelineHelpers(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{`{{ .Name | hasPrefix "Get" }}`, "true"},
+		{`{{ .Name | hasPrefix "List" }}`, "false"},
+		{`{{ .Name | hasSuffix "User" }}`, "true"},
+		{`{{ .Names | join ", " }}`, "a, b"},
+		{`{{ .Name | indent 2 }}`, "  GetUser"},
+	}
+
+	for _, enableSprig := range []bool{false, true} {
+		for _, tt := range tests {
+			tpl, err := parse("test", []byte(tt.text), buildDefaultHelperApi(enableSprig), [2]string{"{{", "}}"})
+			if err != nil {
+				t.Fatalf("parse(%q): %v", tt.text, err)
+			}
+
+			var out strings.Builder
+			data := map[string]interface{}{"Name": "GetUser", "Names": []string{"a", "b"}}
+			if err := tpl.Execute(&out, data); err != nil {
+				t.Fatalf("execute(%q): %v", tt.text, err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("%s (sprig=%v) = %q, want %q", tt.text, enableSprig, out.String(), tt.want)
+			}
+		}
+	}
+}
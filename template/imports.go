@@ -0,0 +1,110 @@
+package template
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// importsMarker is what the imports helper writes into the output. It is
+// replaced by the import block after the template execution, since imports
+// are usually added after the block position.
+const importsMarker = "\x00imports\x00"
+
+// importCollector gathers Go imports used by a template, allowing it to
+// generate files with correct import blocks.
+type importCollector struct {
+	mu      sync.Mutex
+	imports map[string]string
+}
+
+func newImportCollector() *importCollector {
+	return &importCollector{
+		imports: make(map[string]string),
+	}
+}
+
+// add registers an import path, with an optional alias. It returns an empty
+// string so it can be used inside a template action.
+func (c *importCollector) add(path string, alias ...string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.imports[path] = ""
+	if len(alias) > 0 {
+		c.imports[path] = alias[0]
+	}
+
+	return ""
+}
+
+// marker gives the position where the import block must be written.
+func (c *importCollector) marker() string {
+	return importsMarker
+}
+
+func (c *importCollector) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.imports = make(map[string]string)
+}
+
+// replace writes the import block into the marker position of data.
+func (c *importCollector) replace(data []byte) []byte {
+	if !bytes.Contains(data, []byte(importsMarker)) {
+		return data
+	}
+
+	return bytes.ReplaceAll(data, []byte(importsMarker), []byte(c.render()))
+}
+
+// render gives the import block with standard library imports first, both
+// groups sorted.
+func (c *importCollector) render() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.imports) == 0 {
+		return ""
+	}
+
+	var std, others []string
+	for path := range c.imports {
+		if isStdImport(path) {
+			std = append(std, path)
+			continue
+		}
+
+		others = append(others, path)
+	}
+
+	sort.Strings(std)
+	sort.Strings(others)
+
+	var s strings.Builder
+	s.WriteString("import (\n")
+	for i, group := range [][]string{std, others} {
+		if i > 0 && len(std) > 0 && len(others) > 0 {
+			s.WriteString("\n")
+		}
+
+		for _, path := range group {
+			if alias := c.imports[path]; alias != "" {
+				s.WriteString(fmt.Sprintf("\t%s %q\n", alias, path))
+				continue
+			}
+
+			s.WriteString(fmt.Sprintf("\t%q\n", path))
+		}
+	}
+	s.WriteString(")")
+
+	return s.String()
+}
+
+func isStdImport(path string) bool {
+	return !strings.Contains(strings.Split(path, "/")[0], ".")
+}
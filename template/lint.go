@@ -0,0 +1,264 @@
+package template
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	tparse "text/template/parse"
+)
+
+// builtinFunctions are the functions always available inside a template.
+var builtinFunctions = map[string]bool{
+	"and": true, "or": true, "not": true, "len": true, "index": true,
+	"slice": true, "print": true, "printf": true, "println": true,
+	"html": true, "js": true, "urlquery": true, "call": true,
+	"eq": true, "ne": true, "lt": true, "le": true, "gt": true, "ge": true,
+}
+
+var parseErrorLineRegex = regexp.MustCompile(`:(\d+):`)
+
+// LintError describes a problem found inside a template by Validate.
+type LintError struct {
+	Template string
+	Line     int
+	Message  string
+}
+
+func (e *LintError) Error() string {
+	return fmt.Sprintf("template '%s', line %d: %s", e.Template, e.Line, e.Message)
+}
+
+// LintErrors holds all problems found inside templates by Validate.
+type LintErrors []*LintError
+
+func (e LintErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+// Validate parses every template, checking if all referenced functions are
+// available in the helper API. When Options.LintContextFields is enabled,
+// fields accessed from the template context are also checked, using its
+// type. It returns all problems found, as LintErrors, at once.
+func (t *Templates) Validate() error {
+	var errs LintErrors
+
+	for _, template := range t.templates {
+		if template.binary {
+			continue
+		}
+
+		errs = append(errs, t.lint(template)...)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func (t *Templates) lint(template *Info) LintErrors {
+	var (
+		text = string(template.data)
+		tree = tparse.New(template.templateFilename)
+		set  = make(map[string]*tparse.Tree)
+	)
+
+	tree.Mode = tparse.SkipFuncCheck
+	if _, err := tree.Parse(text, t.delimiters[0], t.delimiters[1], set); err != nil {
+		return LintErrors{{
+			Template: template.templateFilename,
+			Line:     parseErrorLine(err),
+			Message:  err.Error(),
+		}}
+	}
+
+	l := &linter{
+		name: template.templateFilename,
+		text: text,
+		api:  template.api,
+	}
+
+	if t.lintFields {
+		ctx, err := t.templateContext(template.templateFilename)
+		if err != nil {
+			return LintErrors{{Template: template.templateFilename, Message: err.Error()}}
+		}
+
+		l.context = reflect.TypeOf(ctx)
+	}
+
+	l.walk(tree.Root, true)
+
+	// Fields are only checked in the main template, since the context of
+	// defined templates depends on how they are called.
+	l.context = nil
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if name != template.templateFilename && set[name].Root != nil {
+			l.walk(set[name].Root, false)
+		}
+	}
+
+	return l.errs
+}
+
+type linter struct {
+	name    string
+	text    string
+	api     map[string]interface{}
+	context reflect.Type
+	errs    LintErrors
+}
+
+// walk checks node and its children. root tells if the dot inside node is
+// still the template context.
+func (l *linter) walk(node tparse.Node, root bool) {
+	switch n := node.(type) {
+	case *tparse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			l.walk(child, root)
+		}
+
+	case *tparse.ActionNode:
+		l.walk(n.Pipe, root)
+
+	case *tparse.IfNode:
+		l.walkBranch(&n.BranchNode, root, root)
+
+	case *tparse.RangeNode:
+		l.walkBranch(&n.BranchNode, root, false)
+
+	case *tparse.WithNode:
+		l.walkBranch(&n.BranchNode, root, false)
+
+	case *tparse.TemplateNode:
+		l.walk(n.Pipe, root)
+
+	case *tparse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			l.walk(cmd, root)
+		}
+
+	case *tparse.CommandNode:
+		for _, arg := range n.Args {
+			l.walk(arg, root)
+		}
+
+	case *tparse.IdentifierNode:
+		if _, ok := l.api[n.Ident]; !ok && !builtinFunctions[n.Ident] {
+			l.addError(n.Position(), fmt.Sprintf("function '%s' is not defined", n.Ident))
+		}
+
+	case *tparse.ChainNode:
+		l.walk(n.Node, root)
+
+	case *tparse.FieldNode:
+		if root {
+			l.checkFields(n.Position(), n.Ident)
+		}
+
+	case *tparse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			l.checkFields(n.Position(), n.Ident[1:])
+		}
+	}
+}
+
+func (l *linter) walkBranch(n *tparse.BranchNode, root, listRoot bool) {
+	l.walk(n.Pipe, root)
+	l.walk(n.List, listRoot)
+	l.walk(n.ElseList, root)
+}
+
+// checkFields checks if the chain of fields (or methods) exists in the
+// context type.
+func (l *linter) checkFields(pos tparse.Pos, fields []string) {
+	t := l.context
+	for _, field := range fields {
+		if t == nil {
+			return
+		}
+
+		if m, ok := methodByName(t, field); ok {
+			if m.Type.NumOut() == 0 {
+				return
+			}
+
+			t = m.Type.Out(0)
+			continue
+		}
+
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		if t.Kind() == reflect.Map || t.Kind() == reflect.Interface {
+			// Maps and interfaces cannot be checked.
+			return
+		}
+		if t.Kind() != reflect.Struct {
+			l.addError(pos, fmt.Sprintf("can't evaluate field '%s' in type %s", field, t))
+			return
+		}
+
+		f, ok := t.FieldByName(field)
+		if !ok || !f.IsExported() {
+			l.addError(pos, fmt.Sprintf("unknown field or method '%s' in type %s", field, t))
+			return
+		}
+
+		t = f.Type
+	}
+}
+
+// methodByName looks for a method considering both value and pointer
+// receivers, since templates can call both when the value is addressable.
+func methodByName(t reflect.Type, name string) (reflect.Method, bool) {
+	if m, ok := t.MethodByName(name); ok {
+		return m, true
+	}
+
+	if t.Kind() != reflect.Pointer && t.Kind() != reflect.Interface {
+		return reflect.PointerTo(t).MethodByName(name)
+	}
+
+	return reflect.Method{}, false
+}
+
+func (l *linter) addError(pos tparse.Pos, msg string) {
+	l.errs = append(l.errs, &LintError{
+		Template: l.name,
+		Line:     1 + strings.Count(l.text[:pos], "\n"),
+		Message:  msg,
+	})
+}
+
+func parseErrorLine(err error) int {
+	m := parseErrorLineRegex.FindStringSubmatch(err.Error())
+	if len(m) < 2 {
+		return 0
+	}
+
+	line, _ := strconv.Atoi(m[1])
+	return line
+}
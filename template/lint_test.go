@@ -0,0 +1,61 @@
+package template
+
+import (
+	"errors"
+	"testing"
+)
+
+type lintContext struct {
+	Name     string
+	Labels   map[string]string
+	Any      interface{}
+	Children []*lintContext
+}
+
+func (lintContext) ValidateForExecute() map[string]TemplateValidator {
+	return nil
+}
+
+func (lintContext) Extension() string {
+	return "go"
+}
+
+func TestValidateContextFields(t *testing.T) {
+	tests := []struct {
+		text    string
+		wantErr string
+	}{
+		{`{{ .Name }}`, ""},
+		{`{{ .Labels.foo }}`, ""},
+		{`{{ .Any.Foo }}`, ""},
+		{`{{ .Missing }}`, "template 'test', line 1: unknown field or method 'Missing' in type template.lintContext"},
+		{`{{ .Name.Foo }}`, "template 'test', line 1: can't evaluate field 'Foo' in type string"},
+		{"\n{{ .Children.Name }}", "template 'test', line 2: can't evaluate field 'Name' in type []*template.lintContext"},
+	}
+
+	for _, tt := range tests {
+		tpls := &Templates{
+			templates: []*Info{{
+				templateFilename: "test",
+				data:             []byte(tt.text),
+				api:              buildDefaultHelperApi(false),
+			}},
+			context:    lintContext{},
+			delimiters: [2]string{"{{", "}}"},
+			lintFields: true,
+		}
+
+		err := tpls.Validate()
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("Validate(%q) = %v, want no error", tt.text, err)
+			}
+			continue
+		}
+
+		var lintErrs LintErrors
+		if !errors.As(err, &lintErrs) || len(lintErrs) != 1 || lintErrs[0].Error() != tt.wantErr {
+			t.Errorf("Validate(%q) = %v, want %q", tt.text, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package template
+
+import (
+	"errors"
+	"path"
+	"regexp"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// ModuleResolver is a function that gives the module name and its import
+// path from the .proto files being handled by a protogen plugin.
+type ModuleResolver func(plugin *protogen.Plugin) (name, path string, err error)
+
+var versionSuffixRegex = regexp.MustCompile(`v\d+((alpha|beta)\d*)?$`)
+
+// DefaultModuleResolver retrieves the module name and path from the
+// go_package option of the main .proto file, whatever its layout is. The
+// module name does not have the version suffix, i.e., a package named
+// 'userv1' is handled as 'user', and a package named only 'v1' uses its
+// parent directory name.
+func DefaultModuleResolver(plugin *protogen.Plugin) (string, string, error) {
+	file := mainProtoFile(plugin)
+	if file == nil {
+		return "", "", errors.New("cannot find the module name without .proto files")
+	}
+
+	importPath := strings.ReplaceAll(file.GoImportPath.String(), "\"", "")
+	name := versionSuffixRegex.ReplaceAllString(string(file.GoPackageName), "")
+	if name == "" {
+		name = path.Base(path.Dir(importPath))
+	}
+
+	return name, importPath, nil
+}
+
+// mainProtoFile gives the last .proto file that protoc asked to generate
+// code for, which is the main file being "compiled".
+func mainProtoFile(plugin *protogen.Plugin) *protogen.File {
+	if len(plugin.Files) == 0 {
+		return nil
+	}
+
+	for i := len(plugin.Files) - 1; i >= 0; i-- {
+		if plugin.Files[i].Generate {
+			return plugin.Files[i]
+		}
+	}
+
+	return plugin.Files[len(plugin.Files)-1]
+}
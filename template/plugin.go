@@ -0,0 +1,30 @@
+package template
+
+import (
+	"errors"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// ExecuteIntoPlugin executes all templates and writes their content directly
+// into the protogen plugin response, as new generated files. It requires
+// that Options.Plugin was set when loading the templates.
+func (t *Templates) ExecuteIntoPlugin() error {
+	if t.plugin == nil {
+		return errors.New("cannot execute into a plugin without a protogen.Plugin")
+	}
+
+	return t.ExecuteFunc(func(g *Generated) error {
+		var importPath protogen.GoImportPath
+
+		// Go outputs must belong to the module package, so protogen can
+		// properly handle their imports.
+		if isGoOutput(g) {
+			importPath = protogen.GoImportPath(t.importPath)
+		}
+
+		file := t.plugin.NewGeneratedFile(g.Filename, importPath)
+		_, err := file.Write(g.Data.Bytes())
+		return err
+	})
+}
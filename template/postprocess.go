@@ -0,0 +1,71 @@
+package template
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+
+	"golang.org/x/tools/imports"
+)
+
+// PostProcessor is a function that can change a generated content before
+// it is returned to the caller.
+type PostProcessor func(gen *Generated) error
+
+// GoFormat formats .go outputs using gofmt rules.
+func GoFormat(gen *Generated) error {
+	if !isGoOutput(gen) {
+		return nil
+	}
+
+	out, err := format.Source(gen.Data.Bytes())
+	if err != nil {
+		return fmt.Errorf("could not format '%s': %w", gen.Filename, err)
+	}
+
+	gen.Data = bytes.NewBuffer(out)
+	return nil
+}
+
+// GoImports formats .go outputs and adjusts their import blocks, adding
+// missing ones and removing unreferenced ones, like goimports does.
+func GoImports(gen *Generated) error {
+	if !isGoOutput(gen) {
+		return nil
+	}
+
+	out, err := imports.Process(gen.Filename, gen.Data.Bytes(), nil)
+	if err != nil {
+		return fmt.Errorf("could not process imports of '%s': %w", gen.Filename, err)
+	}
+
+	gen.Data = bytes.NewBuffer(out)
+	return nil
+}
+
+// NormalizeWhitespace removes trailing whitespaces from every line of the
+// output and ensures that it ends with a single newline. Binary outputs are
+// ignored.
+func NormalizeWhitespace(gen *Generated) error {
+	if gen.Binary {
+		return nil
+	}
+
+	lines := strings.Split(gen.Data.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+
+	out := strings.TrimRight(strings.Join(lines, "\n"), "\n")
+	if out != "" {
+		out += "\n"
+	}
+
+	gen.Data = bytes.NewBufferString(out)
+	return nil
+}
+
+func isGoOutput(gen *Generated) bool {
+	return hasExtension(gen, "go")
+}
@@ -0,0 +1,28 @@
+package template
+
+import (
+	"github.com/Masterminds/sprig/v3"
+)
+
+// sprigPrefix is the namespace where all Sprig functions are always
+// available, even when their names collide with other helper functions.
+const sprigPrefix = "sprig_"
+
+// mergeSprigFunctions adds the Sprig function library into the helper API.
+//
+// Namespacing rules:
+//   - every Sprig function is available with the sprig_ prefix, e.g.,
+//     sprig_trimSuffix;
+//   - Sprig functions are also available with their original names, unless
+//     a default helper already uses it;
+//   - user-provided helper functions always take precedence, since they are
+//     added after this.
+func mergeSprigFunctions(api map[string]interface{}) {
+	for name, fn := range sprig.TxtFuncMap() {
+		api[sprigPrefix+name] = fn
+
+		if _, ok := api[name]; !ok {
+			api[name] = fn
+		}
+	}
+}
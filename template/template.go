@@ -13,6 +13,8 @@ import (
 	"github.com/go-playground/validator/v10"
 	"github.com/iancoleman/strcase"
 	"google.golang.org/protobuf/compiler/protogen"
+
+	"github.com/rsfreitas/go-pocket-utils/converters"
 )
 
 type Options struct {
@@ -23,8 +25,46 @@ type Options struct {
 	Path             string
 	Plugin           *protogen.Plugin
 	Files            embed.FS        `validate:"required"`
-	Context          TemplateContext `validate:"required"`
+	Context          TemplateContext `validate:"required_without=ContextProvider"`
 	HelperFunctions  map[string]interface{}
+
+	// ContextProvider allows each template to receive its own context object.
+	// When set, it takes precedence over Context.
+	ContextProvider ContextProvider
+
+	// FilenameBuilder allows customizing the output filename of every
+	// template. By default, it is '<path>/<prefix>.<templateName>.<extension>'.
+	FilenameBuilder FilenameBuilder
+
+	// ModuleResolver gives the module name and path when Plugin is used. If
+	// not set, DefaultModuleResolver is used.
+	ModuleResolver ModuleResolver
+
+	// Delimiters changes the template action delimiters, left and right
+	// respectively, allowing to generate files where '{{' and '}}' are part
+	// of the target syntax. Empty values use the default ones.
+	Delimiters [2]string
+
+	// LintContextFields enables, in Validate, checking if the fields
+	// accessed inside templates exist in their context type.
+	LintContextFields bool
+
+	// PostProcessors are executed, in order, over every generated output
+	// before it is returned by Execute.
+	PostProcessors []PostProcessor
+
+	// PostValidators are executed over every generated output, after all
+	// post processors, to check if its content is valid.
+	PostValidators []PostValidator
+
+	// EnableSprig adds the Sprig function library (https://masterminds.github.io/sprig/)
+	// into the templates helper functions.
+	EnableSprig bool
+
+	// BinaryFiles holds glob patterns (e.g. "*.png") of files that must be
+	// handled as binary, i.e., they are not parsed as templates and their
+	// content is kept untouched in the output.
+	BinaryFiles []string
 }
 
 // TemplateContext is an interface that a template file context, i.e., the
@@ -36,17 +76,36 @@ type TemplateContext interface {
 
 type TemplateValidator func() bool
 
+// ContextProvider is a function that gives the TemplateContext of a specific
+// template, identified by its name.
+type ContextProvider func(templateName string) (TemplateContext, error)
+
+// FilenameBuilder is a function that gives the output filename of a template.
+type FilenameBuilder func(templateName string, ctx TemplateContext) string
+
 // Templates is an object that holds information related to a group of
 // template files, allowing them to be parsed later.
 type Templates struct {
 	strictValidators bool
 	path             string
+	importPath       string
+	plugin           *protogen.Plugin
 	prefix           string
 	context          TemplateContext
+	contextProvider  ContextProvider
+	filenameBuilder  FilenameBuilder
+	delimiters       [2]string
+	lintFields       bool
+	accumulator      *accumulator
 	templates        []*Info
+	postProcessors   []PostProcessor
+	postValidators   []PostValidator
 }
 
 type Info struct {
+	imports          *importCollector
+	binary           bool
+	filename         string
 	templateFilename string
 	data             []byte
 	api              map[string]interface{}
@@ -58,13 +117,52 @@ type Generated struct {
 	TemplateName string
 	Extension    string
 	Data         *bytes.Buffer
+
+	// Binary indicates that Data holds raw bytes which must not be changed
+	// by text post processors.
+	Binary bool
 }
 
+// Execute executes all templates, giving back their content. Templates that
+// read accumulators, with the accumulated helper function, are executed
+// after all others.
 func (t *Templates) Execute() ([]*Generated, error) {
 	var gen []*Generated
 
-	for _, template := range t.templates {
-		validator, ok := t.context.ValidateForExecute()[template.templateFilename]
+	err := t.execute(func(g *Generated) error {
+		gen = append(gen, g)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return gen, nil
+}
+
+// ExecuteFunc executes all templates like Execute does, but instead of
+// holding every generated content in memory, it yields each one to fn as
+// soon as it is produced. The content buffer is released after fn returns,
+// so fn must not keep references to it.
+func (t *Templates) ExecuteFunc(fn func(gen *Generated) error) error {
+	return t.execute(func(g *Generated) error {
+		defer func() {
+			g.Data = nil
+		}()
+
+		return fn(g)
+	})
+}
+
+func (t *Templates) execute(fn func(gen *Generated) error) error {
+	t.accumulator.reset()
+	for _, template := range t.executionOrder() {
+		tctx, err := t.templateContext(template.templateFilename)
+		if err != nil {
+			return err
+		}
+
+		validator, ok := tctx.ValidateForExecute()[template.templateFilename]
 		if !ok && t.strictValidators {
 			// The validator should be executed in this case, since we don't
 			// have one for this template, we can skip it.
@@ -76,37 +174,122 @@ func (t *Templates) Execute() ([]*Generated, error) {
 			continue
 		}
 
-		tpl, err := parse(template.templateFilename, template.data, template.api)
+		if template.binary {
+			g := t.binaryOutput(template, tctx)
+			if err := t.validate(g); err != nil {
+				return err
+			}
+
+			if err := fn(g); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		tpl, err := parse(template.templateFilename, template.data, template.api, t.delimiters)
 		if err != nil {
-			return nil, err
+			return newExecutionError(template.templateFilename, template.data, err)
 		}
 
 		var buf bytes.Buffer
 		w := bufio.NewWriter(&buf)
 
-		if err := tpl.Execute(w, t.context); err != nil {
-			return nil, err
+		template.imports.reset()
+		if err := tpl.Execute(w, tctx); err != nil {
+			return newExecutionError(template.templateFilename, template.data, err)
 		}
 
 		w.Flush()
 
-		filename := template.templateFilename
-		if t.path != "" {
-			filename = filepath.Join(t.path, fmt.Sprintf("%s.%s", t.prefix, template.templateFilename))
-		}
-		if t.context.Extension() != "" {
-			filename += fmt.Sprintf(".%s", t.context.Extension())
+		filename := t.defaultFilename(template.templateFilename, tctx)
+		if t.filenameBuilder != nil {
+			filename = t.filenameBuilder(template.templateFilename, tctx)
 		}
 
-		gen = append(gen, &Generated{
-			Data:         &buf,
+		g := &Generated{
+			Data:         bytes.NewBuffer(template.imports.replace(buf.Bytes())),
 			Filename:     filename,
 			TemplateName: template.templateFilename,
-			Extension:    t.context.Extension(),
-		})
+			Extension:    tctx.Extension(),
+		}
+
+		for _, p := range t.postProcessors {
+			if err := p(g); err != nil {
+				return err
+			}
+		}
+
+		if err := t.validate(g); err != nil {
+			return err
+		}
+
+		if err := fn(g); err != nil {
+			return err
+		}
 	}
 
-	return gen, nil
+	return nil
+}
+
+func (t *Templates) defaultFilename(templateName string, ctx TemplateContext) string {
+	filename := templateName
+	if t.path != "" {
+		filename = filepath.Join(t.path, fmt.Sprintf("%s.%s", t.prefix, templateName))
+	}
+	if ctx.Extension() != "" {
+		filename += fmt.Sprintf(".%s", ctx.Extension())
+	}
+
+	return filename
+}
+
+// templateContext gives the context that must be used by a template.
+func (t *Templates) templateContext(name string) (TemplateContext, error) {
+	if t.contextProvider == nil {
+		return t.context, nil
+	}
+
+	ctx, err := t.contextProvider(name)
+	if err != nil {
+		return nil, err
+	}
+	if ctx == nil {
+		return nil, fmt.Errorf("no context available for template '%s'", name)
+	}
+
+	return ctx, nil
+}
+
+// validate runs all post validators over a generated content, failing at the
+// first error found.
+func (t *Templates) validate(gen *Generated) error {
+	for _, v := range t.postValidators {
+		if err := v(gen); err != nil {
+			return fmt.Errorf("template '%s' generated an invalid output: %w", gen.TemplateName, err)
+		}
+	}
+
+	return nil
+}
+
+// binaryOutput gives back a binary file content without parsing it.
+func (t *Templates) binaryOutput(template *Info, tctx TemplateContext) *Generated {
+	filename := template.filename
+	if t.path != "" {
+		filename = filepath.Join(t.path, fmt.Sprintf("%s.%s", t.prefix, template.filename))
+	}
+	if t.filenameBuilder != nil {
+		filename = t.filenameBuilder(template.templateFilename, tctx)
+	}
+
+	return &Generated{
+		Data:         bytes.NewBuffer(bytes.Clone(template.data)),
+		Filename:     filename,
+		TemplateName: template.templateFilename,
+		Extension:    strings.TrimPrefix(filepath.Ext(template.filename), "."),
+		Binary:       true,
+	}
 }
 
 func LoadTemplates(options *Options) (*Templates, error) {
@@ -116,20 +299,24 @@ func LoadTemplates(options *Options) (*Templates, error) {
 	}
 
 	var (
-		filename string
-		path     string
+		filename   string
+		path       string
+		importPath string
 	)
 
 	if options.Plugin != nil {
-		var err error
+		resolver := options.ModuleResolver
+		if resolver == nil {
+			resolver = DefaultModuleResolver
+		}
 
-		filename, path, err = GetPackageNameAndPath(options.Plugin)
+		var err error
+		filename, path, err = resolver(options.Plugin)
 		if err != nil {
 			return nil, err
 		}
 
-		// filename should not have the version suffix.
-		filename = strings.TrimSuffix(filename, "v1")
+		importPath = path
 	}
 
 	if options.Path != "" {
@@ -141,7 +328,10 @@ func LoadTemplates(options *Options) (*Templates, error) {
 		return nil, err
 	}
 
-	var tpls []*Info
+	var (
+		tpls []*Info
+		acc  = newAccumulator()
+	)
 
 	for _, t := range templates {
 		data, err := options.Files.ReadFile(t.Name())
@@ -149,17 +339,31 @@ func LoadTemplates(options *Options) (*Templates, error) {
 			return nil, err
 		}
 
-		helperApi := buildDefaultHelperApi()
+		binary, err := isBinaryFile(t.Name(), options.BinaryFiles)
+		if err != nil {
+			return nil, err
+		}
+
+		helperApi := buildDefaultHelperApi(options.EnableSprig)
 		basename := filenameWithoutExtension(t.Name())
 		helperApi["templateName"] = func() string {
 			return basename
 		}
 
+		imports := newImportCollector()
+		helperApi["addImport"] = imports.add
+		helperApi["imports"] = imports.marker
+		helperApi["accumulate"] = acc.add
+		helperApi[accumulatedFunction] = acc.get
+
 		for k, v := range options.HelperFunctions {
 			helperApi[k] = v
 		}
 
 		tpls = append(tpls, &Info{
+			imports:          imports,
+			binary:           binary,
+			filename:         t.Name(),
 			templateFilename: basename,
 			data:             data,
 			api:              helperApi,
@@ -169,28 +373,56 @@ func LoadTemplates(options *Options) (*Templates, error) {
 	return &Templates{
 		templates:        tpls,
 		path:             path,
+		importPath:       importPath,
+		plugin:           options.Plugin,
 		prefix:           filename,
 		context:          options.Context,
+		contextProvider:  options.ContextProvider,
+		filenameBuilder:  options.FilenameBuilder,
+		delimiters:       options.Delimiters,
+		lintFields:       options.LintContextFields,
+		accumulator:      acc,
 		strictValidators: options.StrictValidators,
+		postProcessors:   options.PostProcessors,
+		postValidators:   options.PostValidators,
 	}, nil
 }
 
-func buildDefaultHelperApi() map[string]interface{} {
-	return template.FuncMap{
+func buildDefaultHelperApi(enableSprig bool) map[string]interface{} {
+	api := template.FuncMap{
 		"toLowerCamelCase": strcase.ToLowerCamel,
 		"firstLower": func(s string) string {
 			c := s[0]
 			return strings.ToLower(string(c))
 		},
-		"toSnake":     strcase.ToSnake,
-		"toCamelCase": strcase.ToCamel,
-		"toKebab":     strcase.ToKebab,
-		"trimSuffix":  strings.TrimSuffix,
+		"toSnake":          strcase.ToSnake,
+		"toCamelCase":      strcase.ToCamel,
+		"toKebab":          strcase.ToKebab,
+		"trimSuffix":       strings.TrimSuffix,
+		"uuidv5":           uuidV5,
+		"hashSuffix":       hashSuffix,
+		"stableName":       stableName,
+		"pluralize":        pluralize,
+		"singularize":      singularize,
+		"toScreamingSnake": strcase.ToScreamingSnake,
+		"indent":           indent,
+		"nindent":          nindent,
+		"quote":            quote,
+		"goType":           converters.GoType,
+		"hasPrefix":        hasPrefix,
+		"hasSuffix":        hasSuffix,
+		"join":             join,
 	}
+
+	if enableSprig {
+		mergeSprigFunctions(api)
+	}
+
+	return api
 }
 
-func parse(key string, data []byte, helperApi template.FuncMap) (*template.Template, error) {
-	t, err := template.New(key).Funcs(helperApi).Parse(string(data))
+func parse(key string, data []byte, helperApi template.FuncMap, delimiters [2]string) (*template.Template, error) {
+	t, err := template.New(key).Delims(delimiters[0], delimiters[1]).Funcs(helperApi).Parse(string(data))
 	if err != nil {
 		return nil, err
 	}
@@ -198,12 +430,29 @@ func parse(key string, data []byte, helperApi template.FuncMap) (*template.Templ
 	return t, nil
 }
 
+func isBinaryFile(filename string, patterns []string) (bool, error) {
+	for _, pattern := range patterns {
+		matched, err := filepath.Match(pattern, filename)
+		if err != nil {
+			return false, err
+		}
+		if matched {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 func filenameWithoutExtension(filename string) string {
 	return filename[:len(filename)-len(filepath.Ext(filename))]
 }
 
 // GetPackageNameAndPath try to retrieve the golang module name from the list of .proto
 // files.
+//
+// Deprecated: it only supports the "services/<module>/v1" layout. Use
+// DefaultModuleResolver instead.
 func GetPackageNameAndPath(plugin *protogen.Plugin) (string, string, error) {
 	if len(plugin.Files) == 0 {
 		return "", "", errors.New("cannot find the module name without .proto files")
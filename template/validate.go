@@ -0,0 +1,58 @@
+package template
+
+import (
+	"encoding/json"
+	"errors"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PostValidator is a function that checks if a generated content is valid,
+// returning an error otherwise.
+type PostValidator func(gen *Generated) error
+
+// ValidateGo checks if .go outputs are syntactically valid Go source files.
+func ValidateGo(gen *Generated) error {
+	if !isGoOutput(gen) {
+		return nil
+	}
+
+	_, err := parser.ParseFile(token.NewFileSet(), gen.Filename, gen.Data.Bytes(), parser.AllErrors)
+	return err
+}
+
+// ValidateYAML checks if .yaml and .yml outputs are valid YAML documents.
+func ValidateYAML(gen *Generated) error {
+	if !hasExtension(gen, "yaml", "yml") {
+		return nil
+	}
+
+	var out interface{}
+	return yaml.Unmarshal(gen.Data.Bytes(), &out)
+}
+
+// ValidateJSON checks if .json outputs are valid JSON documents.
+func ValidateJSON(gen *Generated) error {
+	if !hasExtension(gen, "json") {
+		return nil
+	}
+
+	if !json.Valid(gen.Data.Bytes()) {
+		return errors.New("invalid json content")
+	}
+
+	return nil
+}
+
+func hasExtension(gen *Generated, extensions ...string) bool {
+	for _, ext := range extensions {
+		if gen.Extension == ext || filepath.Ext(gen.Filename) == "."+ext {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,111 @@
+package template
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// WriteOptions controls how generated contents are saved.
+type WriteOptions struct {
+	// Overwrite allows replacing files that already exist. When disabled,
+	// existing files are kept untouched and reported as skipped.
+	Overwrite bool
+
+	// SkipUnchanged avoids writing files whose content is the same as the
+	// generated one, keeping their modification time (and build caches)
+	// intact.
+	SkipUnchanged bool
+
+	// DryRun does not write anything, it only reports, as a unified diff,
+	// what would change.
+	DryRun bool
+}
+
+// WriteResult holds what happened with every generated content after
+// calling Write.
+type WriteResult struct {
+	Written []string
+	Skipped []string
+
+	// Diff is only available when WriteOptions.DryRun is enabled.
+	Diff string
+}
+
+// Write saves the generated contents into their respective files, creating
+// all required directories.
+func Write(gen []*Generated, options WriteOptions) (*WriteResult, error) {
+	var (
+		res  WriteResult
+		diff strings.Builder
+	)
+
+	for _, g := range gen {
+		current, exists, err := readCurrent(g.Filename)
+		if err != nil {
+			return nil, err
+		}
+
+		if exists && !options.Overwrite {
+			res.Skipped = append(res.Skipped, g.Filename)
+			continue
+		}
+
+		if exists && options.SkipUnchanged && bytes.Equal(current, g.Data.Bytes()) {
+			res.Skipped = append(res.Skipped, g.Filename)
+			continue
+		}
+
+		if options.DryRun {
+			d, err := unifiedDiff(g.Filename, current, g.Data.Bytes())
+			if err != nil {
+				return nil, err
+			}
+
+			diff.WriteString(d)
+			res.Written = append(res.Written, g.Filename)
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(g.Filename), 0755); err != nil {
+			return nil, err
+		}
+
+		if err := os.WriteFile(g.Filename, g.Data.Bytes(), 0644); err != nil {
+			return nil, err
+		}
+
+		res.Written = append(res.Written, g.Filename)
+	}
+
+	res.Diff = diff.String()
+	return &res, nil
+}
+
+func readCurrent(filename string) ([]byte, bool, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, false, nil
+		}
+
+		return nil, false, err
+	}
+
+	return data, true, nil
+}
+
+func unifiedDiff(filename string, current, generated []byte) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(current)),
+		B:        difflib.SplitLines(string(generated)),
+		FromFile: filename,
+		ToFile:   filename,
+		Context:  3,
+	})
+}